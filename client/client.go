@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,16 +12,48 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const APIEndpoint = "https://api.remove.bg/v1.0/removebg"
+const AccountEndpoint = "https://api.remove.bg/v1.0/account"
 const imageFileParam = "image_file"
+const imageURLParam = "image_url"
 const bgImageFileParam = "bg_image_file"
 
 //go:generate counterfeiter . ClientInterface
 type ClientInterface interface {
-	RemoveFromFile(inputPath string, apiKey string, params map[string]string) ([]byte, string, error)
+	RemoveFromFile(ctx context.Context, inputPath string, apiKey string, params map[string]string) (Result, error)
+	RemoveFromURL(ctx context.Context, imageURL string, apiKey string, params map[string]string) (Result, error)
+	RemoveFromReader(ctx context.Context, image io.Reader, fileName string, apiKey string, params map[string]string) (Result, error)
+	RemoveFromBytes(ctx context.Context, image []byte, fileName string, apiKey string, params map[string]string) (Result, error)
+	Account(ctx context.Context, apiKey string) (Account, error)
+}
+
+// Result is the processed image, along with the metadata the API returns in
+// the response headers
+type Result struct {
+	Data             []byte
+	ContentType      string
+	CreditsCharged   float64
+	Width            int
+	Height           int
+	DetectedType     string
+	ForegroundTop    int
+	ForegroundLeft   int
+	ForegroundWidth  int
+	ForegroundHeight int
+	Duration         time.Duration // Of the API request
+}
+
+// Account is the credit balance of the API key's account
+type Account struct {
+	TotalCredits        float64 `json:"total_credits"`
+	SubscriptionCredits float64 `json:"subscription_credits"`
+	PayAsYouGoCredits   float64 `json:"payg_credits"`
+	FreeAPICalls        int     `json:"free_api_calls"`
 }
 
 type Client struct {
@@ -28,83 +61,185 @@ type Client struct {
 	HTTPClient http.Client
 }
 
-func (c Client) RemoveFromFile(inputPath string, apiKey string, params map[string]string) ([]byte, string, error) {
-	request, err := c.buildRequest(APIEndpoint, apiKey, params, inputPath)
+func (c Client) RemoveFromFile(ctx context.Context, inputPath string, apiKey string, params map[string]string) (Result, error) {
+	file, err := openFile(inputPath)
+	if err != nil {
+		return Result{}, err
+	}
+
+	defer file.Close()
+
+	return c.RemoveFromReader(ctx, file, filepath.Base(inputPath), apiKey, params)
+}
+
+// RemoveFromURL lets the API fetch the image itself, rather than uploading it
+func (c Client) RemoveFromURL(ctx context.Context, imageURL string, apiKey string, params map[string]string) (Result, error) {
+	return c.remove(ctx, apiKey, params, func(writer *multipart.Writer) error {
+		return writer.WriteField(imageURLParam, imageURL)
+	})
+}
+
+// RemoveFromReader streams the image to the API. The file name is only used
+// as a hint for the multipart attachment.
+func (c Client) RemoveFromReader(ctx context.Context, image io.Reader, fileName string, apiKey string, params map[string]string) (Result, error) {
+	return c.remove(ctx, apiKey, params, func(writer *multipart.Writer) error {
+		return attachReader(writer, imageFileParam, fileName, image)
+	})
+}
+
+func (c Client) RemoveFromBytes(ctx context.Context, image []byte, fileName string, apiKey string, params map[string]string) (Result, error) {
+	return c.RemoveFromReader(ctx, bytes.NewReader(image), fileName, apiKey, params)
+}
+
+func (c Client) Account(ctx context.Context, apiKey string) (Account, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", AccountEndpoint, nil)
 	if err != nil {
-		return nil, "", err
+		return Account{}, err
 	}
 
+	request.Header.Add("X-Api-Key", apiKey)
+	request.Header.Add("User-Agent", c.userAgent())
+
 	resp, err := c.HTTPClient.Do(request)
 	if err != nil {
-		return nil, "", err
+		return Account{}, err
 	}
 
 	defer resp.Body.Close()
 
 	statusCode := resp.StatusCode
 	body, err := ioutil.ReadAll(resp.Body)
-	contentType := resp.Header.Get("Content-Type")
+	if err != nil {
+		return Account{}, err
+	}
 
 	if statusCode == 200 {
-		return body, contentType, err
+		return parseAccount(body)
 	} else if statusCode >= 400 && statusCode < 500 {
-		return nil, "", parseJsonErrors(statusCode, body)
+		return Account{}, parseRequestError(resp, body)
 	} else {
-		return nil, "", fmt.Errorf("Unable to process image http_status=%d", statusCode)
+		return Account{}, &RequestError{
+			StatusCode: statusCode,
+			Err:        errors.New("Unable to fetch account"),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
 	}
 }
 
-func (c Client) buildRequest(uri string, apiKey string, params map[string]string, inputPath string) (*http.Request, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+type imageAttacher = func(*multipart.Writer) error
 
-	err := attachFile(writer, imageFileParam, inputPath)
+func (c Client) remove(ctx context.Context, apiKey string, params map[string]string, attachImage imageAttacher) (Result, error) {
+	request, err := c.buildRequest(ctx, APIEndpoint, apiKey, params, attachImage)
 	if err != nil {
-		return nil, err
+		return Result{}, err
 	}
 
+	startedAt := time.Now()
+	resp, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return Result{}, err
+	}
+
+	defer resp.Body.Close()
+
+	statusCode := resp.StatusCode
+	body, err := ioutil.ReadAll(resp.Body)
+
+	if statusCode == 200 {
+		result := parseResult(resp.Header, body)
+		result.Duration = time.Since(startedAt)
+		return result, err
+	} else if statusCode >= 400 && statusCode < 500 {
+		return Result{}, parseRequestError(resp, body)
+	} else {
+		return Result{}, &RequestError{
+			StatusCode: statusCode,
+			Err:        errors.New("Unable to process image"),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+}
+
+// The multipart body is streamed through a pipe as the request is sent, so
+// the image is never buffered in memory
+func (c Client) buildRequest(ctx context.Context, uri string, apiKey string, params map[string]string, attachImage imageAttacher) (*http.Request, error) {
+	var bgImage *os.File
+
 	if len(params[bgImageFileParam]) > 0 {
-		err := attachFile(writer, bgImageFileParam, params[bgImageFileParam])
+		file, err := openFile(params[bgImageFileParam])
 		if err != nil {
 			return nil, err
 		}
-		delete(params, bgImageFileParam)
-	}
 
-	for key, val := range params {
-		_ = writer.WriteField(key, val)
+		bgImage = file
 	}
 
-	err = writer.Close()
-	if err != nil {
-		return nil, err
-	}
+	body, pipeWriter := io.Pipe()
+	writer := multipart.NewWriter(pipeWriter)
 
-	req, err := http.NewRequest("POST", uri, body)
+	req, err := http.NewRequestWithContext(ctx, "POST", uri, body)
 	if err != nil {
+		if bgImage != nil {
+			bgImage.Close()
+		}
+
 		return nil, err
 	}
 
+	go func() {
+		if bgImage != nil {
+			defer bgImage.Close()
+		}
+
+		pipeWriter.CloseWithError(writeMultipart(writer, params, attachImage, bgImage))
+	}()
+
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Add("X-Api-Key", apiKey)
 	req.Header.Add("User-Agent", c.userAgent())
 	return req, err
 }
 
-func attachFile(writer *multipart.Writer, paramName string, filePath string) error {
+func writeMultipart(writer *multipart.Writer, params map[string]string, attachImage imageAttacher, bgImage *os.File) error {
+	err := attachImage(writer)
+	if err != nil {
+		return err
+	}
+
+	if bgImage != nil {
+		err := attachReader(writer, bgImageFileParam, filepath.Base(bgImage.Name()), bgImage)
+		if err != nil {
+			return err
+		}
+	}
+
+	for key, val := range params {
+		if key == bgImageFileParam {
+			continue
+		}
+
+		_ = writer.WriteField(key, val)
+	}
+
+	return writer.Close()
+}
+
+func openFile(filePath string) (*os.File, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return errors.New("Unable to read file")
+		return nil, errors.New("Unable to read file")
 	}
 
-	defer file.Close()
+	return file, nil
+}
 
-	part, err := writer.CreateFormFile(paramName, filepath.Base(filePath))
+func attachReader(writer *multipart.Writer, paramName string, fileName string, reader io.Reader) error {
+	part, err := writer.CreateFormFile(paramName, fileName)
 	if err != nil {
 		return err
 	}
 
-	_, err = io.Copy(part, file)
+	_, err = io.Copy(part, reader)
 	return err
 }
 
@@ -112,6 +247,67 @@ func (c Client) userAgent() string {
 	return fmt.Sprintf("remove-bg-go-%s", c.Version)
 }
 
+func parseResult(header http.Header, body []byte) Result {
+	result := Result{
+		Data:         body,
+		ContentType:  header.Get("Content-Type"),
+		DetectedType: header.Get("X-Type"),
+	}
+
+	result.CreditsCharged, _ = strconv.ParseFloat(header.Get("X-Credits-Charged"), 64)
+	result.Width, _ = strconv.Atoi(header.Get("X-Width"))
+	result.Height, _ = strconv.Atoi(header.Get("X-Height"))
+	result.ForegroundTop, _ = strconv.Atoi(header.Get("X-Foreground-Top"))
+	result.ForegroundLeft, _ = strconv.Atoi(header.Get("X-Foreground-Left"))
+	result.ForegroundWidth, _ = strconv.Atoi(header.Get("X-Foreground-Width"))
+	result.ForegroundHeight, _ = strconv.Atoi(header.Get("X-Foreground-Height"))
+
+	return result
+}
+
+func parseRequestError(resp *http.Response, body []byte) error {
+	err := parseJsonErrors(resp.StatusCode, body)
+
+	requestErr, ok := err.(*RequestError)
+	if !ok {
+		return err
+	}
+
+	requestErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	requestErr.RateLimit = parseRateLimit(resp.Header)
+
+	return requestErr
+}
+
+// Retry-After is either a number of seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if len(value) == 0 {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+func parseRateLimit(header http.Header) RateLimit {
+	rateLimit := RateLimit{}
+	rateLimit.Limit, _ = strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	rateLimit.Remaining, _ = strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rateLimit.Reset = time.Unix(reset, 0)
+	}
+
+	return rateLimit
+}
+
 func parseJsonErrors(statusCode int, body []byte) error {
 	parsedErrorResponse := jsonErrorResponse{}
 	err := json.Unmarshal(body, &parsedErrorResponse)
@@ -119,28 +315,77 @@ func parseJsonErrors(statusCode int, body []byte) error {
 		return err
 	}
 
+	code := ""
 	errorMessages := make([]string, len(parsedErrorResponse.Errors))
 	for i, e := range parsedErrorResponse.Errors {
 		errorMessages[i] = e.Title
+
+		if len(code) == 0 {
+			code = e.Code
+		}
 	}
 
 	message := strings.Join(errorMessages, ", ")
 
 	return &RequestError{
 		StatusCode: statusCode,
+		Code:       code,
 		Err:        errors.New(message),
 	}
 }
 
+func parseAccount(body []byte) (Account, error) {
+	parsed := jsonAccountResponse{}
+	err := json.Unmarshal(body, &parsed)
+	if err != nil {
+		return Account{}, err
+	}
+
+	attributes := parsed.Data.Attributes
+
+	return Account{
+		TotalCredits:        attributes.Credits.Total,
+		SubscriptionCredits: attributes.Credits.Subscription,
+		PayAsYouGoCredits:   attributes.Credits.Payg,
+		FreeAPICalls:        attributes.API.FreeCalls,
+	}, nil
+}
+
+type jsonAccountResponse struct {
+	Data struct {
+		Attributes struct {
+			Credits struct {
+				Total        float64
+				Subscription float64
+				Payg         float64
+			}
+			API struct {
+				FreeCalls int `json:"free_calls"`
+			}
+		}
+	}
+}
+
 type jsonErrorResponse struct {
 	Errors []struct {
 		Title string
+		Code  string
 	}
 }
 
 type RequestError struct {
 	StatusCode int
+	Code       string // The API's error code, when given
 	Err        error
+	RetryAfter time.Duration
+	RateLimit  RateLimit
+}
+
+// RateLimit holds the X-RateLimit-* response headers
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
 }
 
 func (r *RequestError) Error() string {
@@ -150,3 +395,27 @@ func (r *RequestError) Error() string {
 func (r *RequestError) RateLimitExceeded() bool {
 	return r.StatusCode == 429
 }
+
+// OutOfCredits is true when the account can't pay for the image
+func (r *RequestError) OutOfCredits() bool {
+	return r.StatusCode == 402
+}
+
+// AuthenticationFailed is true when the API key is missing or invalid
+func (r *RequestError) AuthenticationFailed() bool {
+	return r.StatusCode == 401 || r.StatusCode == 403
+}
+
+// RetryDelay is how long the API asked us to wait before trying again,
+// preferring Retry-After over the rate limit reset time
+func (r *RequestError) RetryDelay(now time.Time) time.Duration {
+	if r.RetryAfter > 0 {
+		return r.RetryAfter
+	}
+
+	if r.RateLimit.Reset.After(now) {
+		return r.RateLimit.Reset.Sub(now)
+	}
+
+	return 0
+}
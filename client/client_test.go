@@ -1,14 +1,18 @@
 package client_test
 
 import (
+	"context"
 	"fmt"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/remove-bg/go/client"
 	"gopkg.in/h2non/gock.v1"
+	"io/ioutil"
 	"net/http"
+	"os"
 	"path"
 	"runtime"
+	"time"
 )
 
 var _ = Describe("Client", func() {
@@ -40,14 +44,49 @@ var _ = Describe("Client", func() {
 			SetHeader("Content-Type", "image/png").
 			BodyString("data")
 
-		result, contentType, err := subject.RemoveFromFile(fixtureFile, "api-key", map[string]string{})
+		result, err := subject.RemoveFromFile(context.Background(), fixtureFile, "api-key", map[string]string{})
 
 		Expect(err).To(Not(HaveOccurred()))
-		Expect(result).To(Equal([]byte("data")))
-		Expect(contentType).To(Equal("image/png"))
+		Expect(result.Data).To(Equal([]byte("data")))
+		Expect(result.ContentType).To(Equal("image/png"))
 		Expect(gock.IsDone()).To(BeTrue())
 	})
 
+	It("parses the response metadata", func() {
+		gock.New("https://api.remove.bg").
+			Post("/v1.0/removebg").
+			Reply(200).
+			SetHeader("Content-Type", "image/png").
+			SetHeader("X-Credits-Charged", "0.25").
+			SetHeader("X-Width", "640").
+			SetHeader("X-Height", "480").
+			SetHeader("X-Type", "person").
+			SetHeader("X-Foreground-Top", "10").
+			SetHeader("X-Foreground-Left", "20").
+			SetHeader("X-Foreground-Width", "300").
+			SetHeader("X-Foreground-Height", "400").
+			BodyString("data")
+
+		result, err := subject.RemoveFromFile(context.Background(), fixtureFile, "api-key", map[string]string{})
+
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(result.Duration).To(BeNumerically(">", 0))
+
+		result.Duration = 0
+		Expect(result).To(Equal(client.Result{
+			Data:             []byte("data"),
+			ContentType:      "image/png",
+			CreditsCharged:   0.25,
+			Width:            640,
+			Height:           480,
+			DetectedType:     "person",
+			ForegroundTop:    10,
+			ForegroundLeft:   20,
+			ForegroundWidth:  300,
+			ForegroundHeight: 400,
+		}))
+	})
+
 	It("attaches the image file", func() {
 		matcher := newMultipartAttachmentMatcher("image_file", "person-in-field.jpg")
 
@@ -57,12 +96,69 @@ var _ = Describe("Client", func() {
 			Reply(200).
 			BodyString("data")
 
-		_, _, err := subject.RemoveFromFile(fixtureFile, "api-key", map[string]string{})
+		_, err := subject.RemoveFromFile(context.Background(), fixtureFile, "api-key", map[string]string{})
 
 		Expect(err).To(Not(HaveOccurred()))
 		Expect(gock.IsDone()).To(BeTrue())
 	})
 
+	It("sends the image URL instead of a file", func() {
+		gock.New("https://api.remove.bg").
+			Post("/v1.0/removebg").
+			SetMatcher(newFormValueMatcher("image_url", "https://cdn.example/a.jpg")).
+			Reply(200).
+			SetHeader("Content-Type", "image/png").
+			BodyString("data")
+
+		result, err := subject.RemoveFromURL(context.Background(), "https://cdn.example/a.jpg", "api-key", map[string]string{})
+
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(result.Data).To(Equal([]byte("data")))
+		Expect(result.ContentType).To(Equal("image/png"))
+		Expect(gock.IsDone()).To(BeTrue())
+	})
+
+	It("streams the image from a reader", func() {
+		matcher := newMultipartAttachmentMatcher("image_file", "upload.jpg")
+
+		gock.New("https://api.remove.bg").
+			Post("/v1.0/removebg").
+			SetMatcher(matcher).
+			Reply(200).
+			SetHeader("Content-Type", "image/png").
+			BodyString("data")
+
+		image, err := os.Open(fixtureFile)
+		Expect(err).ToNot(HaveOccurred())
+		defer image.Close()
+
+		result, err := subject.RemoveFromReader(context.Background(), image, "upload.jpg", "api-key", map[string]string{})
+
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(result.Data).To(Equal([]byte("data")))
+		Expect(result.ContentType).To(Equal("image/png"))
+		Expect(gock.IsDone()).To(BeTrue())
+	})
+
+	It("attaches the image from bytes", func() {
+		matcher := newMultipartAttachmentMatcher("image_file", "upload.jpg")
+
+		gock.New("https://api.remove.bg").
+			Post("/v1.0/removebg").
+			SetMatcher(matcher).
+			Reply(200).
+			BodyString("data")
+
+		image, err := ioutil.ReadFile(fixtureFile)
+		Expect(err).ToNot(HaveOccurred())
+
+		result, err := subject.RemoveFromBytes(context.Background(), image, "upload.jpg", "api-key", map[string]string{})
+
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(result.Data).To(Equal([]byte("data")))
+		Expect(gock.IsDone()).To(BeTrue())
+	})
+
 	It("attaches a background image file if specified", func() {
 		imageMatcher := newMultipartAttachmentMatcher("image_file", "person-in-field.jpg")
 		bgImageMatcher := newMultipartAttachmentMatcher("bg_image_file", "background.jpg")
@@ -78,7 +174,7 @@ var _ = Describe("Client", func() {
 			"bg_image_file": bgFixtureFile,
 		}
 
-		_, _, err := subject.RemoveFromFile(fixtureFile, "api-key", params)
+		_, err := subject.RemoveFromFile(context.Background(), fixtureFile, "api-key", params)
 
 		Expect(err).To(Not(HaveOccurred()))
 		Expect(gock.IsDone()).To(BeTrue())
@@ -91,7 +187,7 @@ var _ = Describe("Client", func() {
 			Reply(200).
 			BodyString("data")
 
-		subject.RemoveFromFile(fixtureFile, "api-key", map[string]string{})
+		subject.RemoveFromFile(context.Background(), fixtureFile, "api-key", map[string]string{})
 
 		Expect(gock.IsDone()).To(BeTrue())
 	})
@@ -102,10 +198,14 @@ var _ = Describe("Client", func() {
 				Post("/v1.0/removebg").
 				Reply(500)
 
-			result, _, err := subject.RemoveFromFile(fixtureFile, "api-key", map[string]string{})
+			result, err := subject.RemoveFromFile(context.Background(), fixtureFile, "api-key", map[string]string{})
+
+			Expect(result).To(Equal(client.Result{}))
+			Expect(err).To(MatchError("500: Unable to process image"))
 
-			Expect(result).To(BeNil())
-			Expect(err).To(MatchError("Unable to process image http_status=500"))
+			re, ok := err.(*client.RequestError)
+			Expect(ok).To(BeTrue())
+			Expect(re.StatusCode).To(Equal(500))
 		})
 	})
 
@@ -118,9 +218,9 @@ var _ = Describe("Client", func() {
 				Reply(400).
 				BodyString(jsonError)
 
-			result, _, err := subject.RemoveFromFile(fixtureFile, "api-key", map[string]string{})
+			result, err := subject.RemoveFromFile(context.Background(), fixtureFile, "api-key", map[string]string{})
 
-			Expect(result).To(BeNil())
+			Expect(result).To(Equal(client.Result{}))
 
 			re, ok := err.(*client.RequestError)
 			Expect(ok).To(BeTrue())
@@ -128,14 +228,163 @@ var _ = Describe("Client", func() {
 			Expect(re.Error()).To(Equal("400: File too large, Second error"))
 			Expect(re.StatusCode).To(Equal(400))
 		})
+
+		It("exposes the first error code", func() {
+			gock.New("https://api.remove.bg").
+				Post("/v1.0/removebg").
+				Reply(400).
+				BodyString(`{"errors": [{"title": "Could not identify foreground", "code": "unknown_foreground"}]}`)
+
+			_, err := subject.RemoveFromFile(context.Background(), fixtureFile, "api-key", map[string]string{})
+
+			re, ok := err.(*client.RequestError)
+			Expect(ok).To(BeTrue())
+			Expect(re.Code).To(Equal("unknown_foreground"))
+		})
+	})
+
+	Context("rate limit exceeded", func() {
+		BeforeEach(func() {
+			gock.New("https://api.remove.bg").
+				Post("/v1.0/removebg").
+				Reply(429).
+				SetHeader("Retry-After", "30").
+				SetHeader("X-RateLimit-Limit", "500").
+				SetHeader("X-RateLimit-Remaining", "0").
+				SetHeader("X-RateLimit-Reset", "1600000000").
+				BodyString(`{"errors": [{"title": "Rate limit exceeded"}]}`)
+		})
+
+		It("exposes the rate limit headers", func() {
+			_, err := subject.RemoveFromFile(context.Background(), fixtureFile, "api-key", map[string]string{})
+
+			re, ok := err.(*client.RequestError)
+			Expect(ok).To(BeTrue())
+
+			Expect(re.RateLimitExceeded()).To(BeTrue())
+			Expect(re.RetryAfter).To(Equal(30 * time.Second))
+			Expect(re.RateLimit.Limit).To(Equal(500))
+			Expect(re.RateLimit.Remaining).To(Equal(0))
+			Expect(re.RateLimit.Reset).To(Equal(time.Unix(1600000000, 0)))
+		})
+	})
+
+	Describe("RequestError", func() {
+		now := time.Unix(1600000000, 0)
+
+		It("prefers Retry-After for the retry delay", func() {
+			re := client.RequestError{
+				RetryAfter: 5 * time.Second,
+				RateLimit:  client.RateLimit{Reset: now.Add(time.Minute)},
+			}
+
+			Expect(re.RetryDelay(now)).To(Equal(5 * time.Second))
+		})
+
+		It("falls back to the rate limit reset time", func() {
+			re := client.RequestError{
+				RateLimit: client.RateLimit{Reset: now.Add(time.Minute)},
+			}
+
+			Expect(re.RetryDelay(now)).To(Equal(time.Minute))
+		})
+
+		It("is zero when the API gave no hints", func() {
+			re := client.RequestError{}
+
+			Expect(re.RetryDelay(now)).To(BeZero())
+		})
+
+		It("treats 401 and 403 as authentication failures", func() {
+			Expect((&client.RequestError{StatusCode: 401}).AuthenticationFailed()).To(BeTrue())
+			Expect((&client.RequestError{StatusCode: 403}).AuthenticationFailed()).To(BeTrue())
+			Expect((&client.RequestError{StatusCode: 402}).AuthenticationFailed()).To(BeFalse())
+		})
+
+		It("treats 402 as out of credits", func() {
+			Expect((&client.RequestError{StatusCode: 402}).OutOfCredits()).To(BeTrue())
+			Expect((&client.RequestError{StatusCode: 429}).OutOfCredits()).To(BeFalse())
+		})
+	})
+
+	It("sends the request with the given context", func() {
+		type contextKey string
+		ctx := context.WithValue(context.Background(), contextKey("batch"), "batch-1")
+
+		matcher := gock.NewBasicMatcher()
+		matcher.Add(func(req *http.Request, ereq *gock.Request) (bool, error) {
+			return req.Context().Value(contextKey("batch")) == "batch-1", nil
+		})
+
+		gock.New("https://api.remove.bg").
+			Post("/v1.0/removebg").
+			SetMatcher(matcher).
+			Reply(200).
+			BodyString("data")
+
+		_, err := subject.RemoveFromFile(ctx, fixtureFile, "api-key", map[string]string{})
+
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(gock.IsDone()).To(BeTrue())
+	})
+
+	Describe("Account", func() {
+		It("fetches the account credits", func() {
+			accountJSON := `{"data": {"attributes": {
+				"credits": {"total": 200, "subscription": 150.5, "payg": 49.5},
+				"api": {"free_calls": 50, "sizes": "all"}
+			}}}`
+
+			gock.New("https://api.remove.bg").
+				Get("/v1.0/account").
+				MatchHeader("X-Api-Key", "^api-key$").
+				MatchHeader("User-Agent", "remove-bg-go-x.y.z").
+				Reply(200).
+				BodyString(accountJSON)
+
+			account, err := subject.Account(context.Background(), "api-key")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(account).To(Equal(client.Account{
+				TotalCredits:        200,
+				SubscriptionCredits: 150.5,
+				PayAsYouGoCredits:   49.5,
+				FreeAPICalls:        50,
+			}))
+			Expect(gock.IsDone()).To(BeTrue())
+		})
+
+		It("parses the JSON error messages", func() {
+			gock.New("https://api.remove.bg").
+				Get("/v1.0/account").
+				Reply(403).
+				BodyString(`{"errors": [{"title": "API Key invalid"}]}`)
+
+			_, err := subject.Account(context.Background(), "api-key")
+
+			Expect(err).To(MatchError("403: API Key invalid"))
+		})
 	})
 
 	Context("input file doesn't exist", func() {
 		It("returns a clear error", func() {
 			nonExistentFile := "/tmp/not-a-file"
-			result, _, err := subject.RemoveFromFile(nonExistentFile, "api-key", map[string]string{})
+			result, err := subject.RemoveFromFile(context.Background(), nonExistentFile, "api-key", map[string]string{})
+
+			Expect(result).To(Equal(client.Result{}))
+			Expect(err).To(MatchError("Unable to read file"))
+		})
+	})
+
+	Context("background image file doesn't exist", func() {
+		It("returns a clear error", func() {
+			params := map[string]string{
+				"bg_image_file": "/tmp/not-a-file",
+			}
+
+			result, err := subject.RemoveFromFile(context.Background(), fixtureFile, "api-key", params)
 
-			Expect(result).To(BeNil())
+			Expect(result).To(Equal(client.Result{}))
 			Expect(err).To(MatchError("Unable to read file"))
 		})
 	})
@@ -165,3 +414,19 @@ func newMultipartAttachmentMatcher(key string, expectedFilename string) *gock.Mo
 
 	return matcher
 }
+
+func newFormValueMatcher(key string, expectedValue string) *gock.MockMatcher {
+	matcher := gock.NewBasicMatcher()
+
+	matcher.Add(func(req *http.Request, ereq *gock.Request) (bool, error) {
+		value := req.FormValue(key)
+
+		if value == expectedValue {
+			return true, nil
+		} else {
+			return false, fmt.Errorf("Form value %s was: %s", key, value)
+		}
+	})
+
+	return matcher
+}
@@ -2,51 +2,256 @@
 package clientfakes
 
 import (
+	"context"
+	"io"
 	"sync"
 
 	"github.com/remove-bg/go/client"
 )
 
 type FakeClientInterface struct {
-	RemoveFromFileStub        func(string, string, map[string]string) ([]byte, string, error)
+	AccountStub        func(context.Context, string) (client.Account, error)
+	accountMutex       sync.RWMutex
+	accountArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	accountReturns struct {
+		result1 client.Account
+		result2 error
+	}
+	accountReturnsOnCall map[int]struct {
+		result1 client.Account
+		result2 error
+	}
+	RemoveFromBytesStub        func(context.Context, []byte, string, string, map[string]string) (client.Result, error)
+	removeFromBytesMutex       sync.RWMutex
+	removeFromBytesArgsForCall []struct {
+		arg1 context.Context
+		arg2 []byte
+		arg3 string
+		arg4 string
+		arg5 map[string]string
+	}
+	removeFromBytesReturns struct {
+		result1 client.Result
+		result2 error
+	}
+	removeFromBytesReturnsOnCall map[int]struct {
+		result1 client.Result
+		result2 error
+	}
+	RemoveFromFileStub        func(context.Context, string, string, map[string]string) (client.Result, error)
 	removeFromFileMutex       sync.RWMutex
 	removeFromFileArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-		arg3 map[string]string
+		arg3 string
+		arg4 map[string]string
 	}
 	removeFromFileReturns struct {
-		result1 []byte
-		result2 string
-		result3 error
+		result1 client.Result
+		result2 error
 	}
 	removeFromFileReturnsOnCall map[int]struct {
-		result1 []byte
-		result2 string
-		result3 error
+		result1 client.Result
+		result2 error
+	}
+	RemoveFromReaderStub        func(context.Context, io.Reader, string, string, map[string]string) (client.Result, error)
+	removeFromReaderMutex       sync.RWMutex
+	removeFromReaderArgsForCall []struct {
+		arg1 context.Context
+		arg2 io.Reader
+		arg3 string
+		arg4 string
+		arg5 map[string]string
+	}
+	removeFromReaderReturns struct {
+		result1 client.Result
+		result2 error
+	}
+	removeFromReaderReturnsOnCall map[int]struct {
+		result1 client.Result
+		result2 error
+	}
+	RemoveFromURLStub        func(context.Context, string, string, map[string]string) (client.Result, error)
+	removeFromURLMutex       sync.RWMutex
+	removeFromURLArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 map[string]string
+	}
+	removeFromURLReturns struct {
+		result1 client.Result
+		result2 error
+	}
+	removeFromURLReturnsOnCall map[int]struct {
+		result1 client.Result
+		result2 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeClientInterface) RemoveFromFile(arg1 string, arg2 string, arg3 map[string]string) ([]byte, string, error) {
+func (fake *FakeClientInterface) Account(arg1 context.Context, arg2 string) (client.Account, error) {
+	fake.accountMutex.Lock()
+	ret, specificReturn := fake.accountReturnsOnCall[len(fake.accountArgsForCall)]
+	fake.accountArgsForCall = append(fake.accountArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.AccountStub
+	fakeReturns := fake.accountReturns
+	fake.recordInvocation("Account", []interface{}{arg1, arg2})
+	fake.accountMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClientInterface) AccountCallCount() int {
+	fake.accountMutex.RLock()
+	defer fake.accountMutex.RUnlock()
+	return len(fake.accountArgsForCall)
+}
+
+func (fake *FakeClientInterface) AccountCalls(stub func(context.Context, string) (client.Account, error)) {
+	fake.accountMutex.Lock()
+	defer fake.accountMutex.Unlock()
+	fake.AccountStub = stub
+}
+
+func (fake *FakeClientInterface) AccountArgsForCall(i int) (context.Context, string) {
+	fake.accountMutex.RLock()
+	defer fake.accountMutex.RUnlock()
+	argsForCall := fake.accountArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClientInterface) AccountReturns(result1 client.Account, result2 error) {
+	fake.accountMutex.Lock()
+	defer fake.accountMutex.Unlock()
+	fake.AccountStub = nil
+	fake.accountReturns = struct {
+		result1 client.Account
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClientInterface) AccountReturnsOnCall(i int, result1 client.Account, result2 error) {
+	fake.accountMutex.Lock()
+	defer fake.accountMutex.Unlock()
+	fake.AccountStub = nil
+	if fake.accountReturnsOnCall == nil {
+		fake.accountReturnsOnCall = make(map[int]struct {
+			result1 client.Account
+			result2 error
+		})
+	}
+	fake.accountReturnsOnCall[i] = struct {
+		result1 client.Account
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClientInterface) RemoveFromBytes(arg1 context.Context, arg2 []byte, arg3 string, arg4 string, arg5 map[string]string) (client.Result, error) {
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.removeFromBytesMutex.Lock()
+	ret, specificReturn := fake.removeFromBytesReturnsOnCall[len(fake.removeFromBytesArgsForCall)]
+	fake.removeFromBytesArgsForCall = append(fake.removeFromBytesArgsForCall, struct {
+		arg1 context.Context
+		arg2 []byte
+		arg3 string
+		arg4 string
+		arg5 map[string]string
+	}{arg1, arg2Copy, arg3, arg4, arg5})
+	stub := fake.RemoveFromBytesStub
+	fakeReturns := fake.removeFromBytesReturns
+	fake.recordInvocation("RemoveFromBytes", []interface{}{arg1, arg2Copy, arg3, arg4, arg5})
+	fake.removeFromBytesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClientInterface) RemoveFromBytesCallCount() int {
+	fake.removeFromBytesMutex.RLock()
+	defer fake.removeFromBytesMutex.RUnlock()
+	return len(fake.removeFromBytesArgsForCall)
+}
+
+func (fake *FakeClientInterface) RemoveFromBytesCalls(stub func(context.Context, []byte, string, string, map[string]string) (client.Result, error)) {
+	fake.removeFromBytesMutex.Lock()
+	defer fake.removeFromBytesMutex.Unlock()
+	fake.RemoveFromBytesStub = stub
+}
+
+func (fake *FakeClientInterface) RemoveFromBytesArgsForCall(i int) (context.Context, []byte, string, string, map[string]string) {
+	fake.removeFromBytesMutex.RLock()
+	defer fake.removeFromBytesMutex.RUnlock()
+	argsForCall := fake.removeFromBytesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeClientInterface) RemoveFromBytesReturns(result1 client.Result, result2 error) {
+	fake.removeFromBytesMutex.Lock()
+	defer fake.removeFromBytesMutex.Unlock()
+	fake.RemoveFromBytesStub = nil
+	fake.removeFromBytesReturns = struct {
+		result1 client.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClientInterface) RemoveFromBytesReturnsOnCall(i int, result1 client.Result, result2 error) {
+	fake.removeFromBytesMutex.Lock()
+	defer fake.removeFromBytesMutex.Unlock()
+	fake.RemoveFromBytesStub = nil
+	if fake.removeFromBytesReturnsOnCall == nil {
+		fake.removeFromBytesReturnsOnCall = make(map[int]struct {
+			result1 client.Result
+			result2 error
+		})
+	}
+	fake.removeFromBytesReturnsOnCall[i] = struct {
+		result1 client.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClientInterface) RemoveFromFile(arg1 context.Context, arg2 string, arg3 string, arg4 map[string]string) (client.Result, error) {
 	fake.removeFromFileMutex.Lock()
 	ret, specificReturn := fake.removeFromFileReturnsOnCall[len(fake.removeFromFileArgsForCall)]
 	fake.removeFromFileArgsForCall = append(fake.removeFromFileArgsForCall, struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-		arg3 map[string]string
-	}{arg1, arg2, arg3})
-	fake.recordInvocation("RemoveFromFile", []interface{}{arg1, arg2, arg3})
+		arg3 string
+		arg4 map[string]string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RemoveFromFileStub
+	fakeReturns := fake.removeFromFileReturns
+	fake.recordInvocation("RemoveFromFile", []interface{}{arg1, arg2, arg3, arg4})
 	fake.removeFromFileMutex.Unlock()
-	if fake.RemoveFromFileStub != nil {
-		return fake.RemoveFromFileStub(arg1, arg2, arg3)
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2, ret.result3
+		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.removeFromFileReturns
-	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeClientInterface) RemoveFromFileCallCount() int {
@@ -55,53 +260,193 @@ func (fake *FakeClientInterface) RemoveFromFileCallCount() int {
 	return len(fake.removeFromFileArgsForCall)
 }
 
-func (fake *FakeClientInterface) RemoveFromFileCalls(stub func(string, string, map[string]string) ([]byte, string, error)) {
+func (fake *FakeClientInterface) RemoveFromFileCalls(stub func(context.Context, string, string, map[string]string) (client.Result, error)) {
 	fake.removeFromFileMutex.Lock()
 	defer fake.removeFromFileMutex.Unlock()
 	fake.RemoveFromFileStub = stub
 }
 
-func (fake *FakeClientInterface) RemoveFromFileArgsForCall(i int) (string, string, map[string]string) {
+func (fake *FakeClientInterface) RemoveFromFileArgsForCall(i int) (context.Context, string, string, map[string]string) {
 	fake.removeFromFileMutex.RLock()
 	defer fake.removeFromFileMutex.RUnlock()
 	argsForCall := fake.removeFromFileArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
-func (fake *FakeClientInterface) RemoveFromFileReturns(result1 []byte, result2 string, result3 error) {
+func (fake *FakeClientInterface) RemoveFromFileReturns(result1 client.Result, result2 error) {
 	fake.removeFromFileMutex.Lock()
 	defer fake.removeFromFileMutex.Unlock()
 	fake.RemoveFromFileStub = nil
 	fake.removeFromFileReturns = struct {
-		result1 []byte
-		result2 string
-		result3 error
-	}{result1, result2, result3}
+		result1 client.Result
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeClientInterface) RemoveFromFileReturnsOnCall(i int, result1 []byte, result2 string, result3 error) {
+func (fake *FakeClientInterface) RemoveFromFileReturnsOnCall(i int, result1 client.Result, result2 error) {
 	fake.removeFromFileMutex.Lock()
 	defer fake.removeFromFileMutex.Unlock()
 	fake.RemoveFromFileStub = nil
 	if fake.removeFromFileReturnsOnCall == nil {
 		fake.removeFromFileReturnsOnCall = make(map[int]struct {
-			result1 []byte
-			result2 string
-			result3 error
+			result1 client.Result
+			result2 error
 		})
 	}
 	fake.removeFromFileReturnsOnCall[i] = struct {
-		result1 []byte
-		result2 string
-		result3 error
-	}{result1, result2, result3}
+		result1 client.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClientInterface) RemoveFromReader(arg1 context.Context, arg2 io.Reader, arg3 string, arg4 string, arg5 map[string]string) (client.Result, error) {
+	fake.removeFromReaderMutex.Lock()
+	ret, specificReturn := fake.removeFromReaderReturnsOnCall[len(fake.removeFromReaderArgsForCall)]
+	fake.removeFromReaderArgsForCall = append(fake.removeFromReaderArgsForCall, struct {
+		arg1 context.Context
+		arg2 io.Reader
+		arg3 string
+		arg4 string
+		arg5 map[string]string
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.RemoveFromReaderStub
+	fakeReturns := fake.removeFromReaderReturns
+	fake.recordInvocation("RemoveFromReader", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.removeFromReaderMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClientInterface) RemoveFromReaderCallCount() int {
+	fake.removeFromReaderMutex.RLock()
+	defer fake.removeFromReaderMutex.RUnlock()
+	return len(fake.removeFromReaderArgsForCall)
+}
+
+func (fake *FakeClientInterface) RemoveFromReaderCalls(stub func(context.Context, io.Reader, string, string, map[string]string) (client.Result, error)) {
+	fake.removeFromReaderMutex.Lock()
+	defer fake.removeFromReaderMutex.Unlock()
+	fake.RemoveFromReaderStub = stub
+}
+
+func (fake *FakeClientInterface) RemoveFromReaderArgsForCall(i int) (context.Context, io.Reader, string, string, map[string]string) {
+	fake.removeFromReaderMutex.RLock()
+	defer fake.removeFromReaderMutex.RUnlock()
+	argsForCall := fake.removeFromReaderArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeClientInterface) RemoveFromReaderReturns(result1 client.Result, result2 error) {
+	fake.removeFromReaderMutex.Lock()
+	defer fake.removeFromReaderMutex.Unlock()
+	fake.RemoveFromReaderStub = nil
+	fake.removeFromReaderReturns = struct {
+		result1 client.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClientInterface) RemoveFromReaderReturnsOnCall(i int, result1 client.Result, result2 error) {
+	fake.removeFromReaderMutex.Lock()
+	defer fake.removeFromReaderMutex.Unlock()
+	fake.RemoveFromReaderStub = nil
+	if fake.removeFromReaderReturnsOnCall == nil {
+		fake.removeFromReaderReturnsOnCall = make(map[int]struct {
+			result1 client.Result
+			result2 error
+		})
+	}
+	fake.removeFromReaderReturnsOnCall[i] = struct {
+		result1 client.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClientInterface) RemoveFromURL(arg1 context.Context, arg2 string, arg3 string, arg4 map[string]string) (client.Result, error) {
+	fake.removeFromURLMutex.Lock()
+	ret, specificReturn := fake.removeFromURLReturnsOnCall[len(fake.removeFromURLArgsForCall)]
+	fake.removeFromURLArgsForCall = append(fake.removeFromURLArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 map[string]string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RemoveFromURLStub
+	fakeReturns := fake.removeFromURLReturns
+	fake.recordInvocation("RemoveFromURL", []interface{}{arg1, arg2, arg3, arg4})
+	fake.removeFromURLMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClientInterface) RemoveFromURLCallCount() int {
+	fake.removeFromURLMutex.RLock()
+	defer fake.removeFromURLMutex.RUnlock()
+	return len(fake.removeFromURLArgsForCall)
+}
+
+func (fake *FakeClientInterface) RemoveFromURLCalls(stub func(context.Context, string, string, map[string]string) (client.Result, error)) {
+	fake.removeFromURLMutex.Lock()
+	defer fake.removeFromURLMutex.Unlock()
+	fake.RemoveFromURLStub = stub
+}
+
+func (fake *FakeClientInterface) RemoveFromURLArgsForCall(i int) (context.Context, string, string, map[string]string) {
+	fake.removeFromURLMutex.RLock()
+	defer fake.removeFromURLMutex.RUnlock()
+	argsForCall := fake.removeFromURLArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeClientInterface) RemoveFromURLReturns(result1 client.Result, result2 error) {
+	fake.removeFromURLMutex.Lock()
+	defer fake.removeFromURLMutex.Unlock()
+	fake.RemoveFromURLStub = nil
+	fake.removeFromURLReturns = struct {
+		result1 client.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClientInterface) RemoveFromURLReturnsOnCall(i int, result1 client.Result, result2 error) {
+	fake.removeFromURLMutex.Lock()
+	defer fake.removeFromURLMutex.Unlock()
+	fake.RemoveFromURLStub = nil
+	if fake.removeFromURLReturnsOnCall == nil {
+		fake.removeFromURLReturnsOnCall = make(map[int]struct {
+			result1 client.Result
+			result2 error
+		})
+	}
+	fake.removeFromURLReturnsOnCall[i] = struct {
+		result1 client.Result
+		result2 error
+	}{result1, result2}
 }
 
 func (fake *FakeClientInterface) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.accountMutex.RLock()
+	defer fake.accountMutex.RUnlock()
+	fake.removeFromBytesMutex.RLock()
+	defer fake.removeFromBytesMutex.RUnlock()
 	fake.removeFromFileMutex.RLock()
 	defer fake.removeFromFileMutex.RUnlock()
+	fake.removeFromReaderMutex.RLock()
+	defer fake.removeFromReaderMutex.RUnlock()
+	fake.removeFromURLMutex.RLock()
+	defer fake.removeFromURLMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/remove-bg/go/client"
+	"github.com/spf13/cobra"
+	"io"
+	"net/http"
+)
+
+var accountJSON bool
+
+var accountCmd = &cobra.Command{
+	Short: "Shows the credits remaining on the account",
+	Use:   "account",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := loadAPIKeys(cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+
+		c := client.Client{
+			Version:    cmd.Root().Version,
+			HTTPClient: http.Client{},
+		}
+
+		account, err := c.Account(cmd.Context(), apiKey)
+		if err != nil {
+			return err
+		}
+
+		return printAccount(cmd.OutOrStdout(), account, accountJSON)
+	},
+}
+
+func printAccount(w io.Writer, account client.Account, asJSON bool) error {
+	if asJSON {
+		return json.NewEncoder(w).Encode(account)
+	}
+
+	_, err := fmt.Fprintf(w,
+		"Total credits:         %g\nSubscription credits:  %g\nPay-as-you-go credits: %g\nFree API calls:        %d\n",
+		account.TotalCredits, account.SubscriptionCredits, account.PayAsYouGoCredits, account.FreeAPICalls)
+
+	return err
+}
+
+func init() {
+	accountCmd.Flags().BoolVar(&accountJSON, "json", false, "Output as JSON")
+	RootCmd.AddCommand(accountCmd)
+}
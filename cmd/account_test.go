@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"bytes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/client"
+)
+
+var _ = Describe("printAccount", func() {
+	account := client.Account{
+		TotalCredits:        200,
+		SubscriptionCredits: 150.5,
+		PayAsYouGoCredits:   49.5,
+		FreeAPICalls:        50,
+	}
+
+	It("prints the credits", func() {
+		out := &bytes.Buffer{}
+
+		Expect(printAccount(out, account, false)).To(Succeed())
+
+		Expect(out.String()).To(ContainSubstring("Total credits:         200\n"))
+		Expect(out.String()).To(ContainSubstring("Subscription credits:  150.5\n"))
+		Expect(out.String()).To(ContainSubstring("Pay-as-you-go credits: 49.5\n"))
+		Expect(out.String()).To(ContainSubstring("Free API calls:        50\n"))
+	})
+
+	It("prints JSON for scripts", func() {
+		out := &bytes.Buffer{}
+
+		Expect(printAccount(out, account, true)).To(Succeed())
+
+		Expect(out.String()).To(MatchJSON(`{
+			"total_credits": 200,
+			"subscription_credits": 150.5,
+			"payg_credits": 49.5,
+			"free_api_calls": 50
+		}`))
+	})
+})
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/remove-bg/go/config"
+	"io"
+	"os"
+)
+
+var apiKeyFile string
+
+var errNoAPIKey = errors.New("API key must be specified")
+
+// loadAPIKeys from --api-key-file, else --api-key (or the environment or
+// config files), else the credentials store. apiKey is set to the first key,
+// for commands which only use one.
+func loadAPIKeys(stderr io.Writer) ([]string, error) {
+	path := apiKeyFile
+
+	if len(path) == 0 {
+		if len(apiKey) > 0 {
+			return []string{apiKey}, nil
+		}
+
+		path = config.CredentialsPath()
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, errNoAPIKey
+		}
+	}
+
+	keys, err := config.ReadKeys(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read API keys: %s", err)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no API keys in %s", path)
+	}
+
+	if config.ReadableByOthers(path) {
+		fmt.Fprintf(stderr, "Warning: %s is readable by other users, restrict it with: chmod 600 %s\n", path, path)
+	}
+
+	apiKey = keys[0]
+	return keys, nil
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/config"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var _ = Describe("loadAPIKeys", func() {
+	var (
+		tmpDir            string
+		stderr            *bytes.Buffer
+		previousConfigDir string
+	)
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "api-key-spec")
+		Expect(err).ToNot(HaveOccurred())
+
+		tmpDir = dir
+		stderr = &bytes.Buffer{}
+		previousConfigDir = os.Getenv("XDG_CONFIG_HOME")
+		os.Setenv("XDG_CONFIG_HOME", tmpDir)
+		apiKey, apiKeyFile = "", ""
+	})
+
+	AfterEach(func() {
+		os.Setenv("XDG_CONFIG_HOME", previousConfigDir)
+		apiKey, apiKeyFile = "", ""
+		os.RemoveAll(tmpDir)
+	})
+
+	writeKeys := func(name string, contents string, perm os.FileMode) string {
+		path := filepath.Join(tmpDir, name)
+		Expect(ioutil.WriteFile(path, []byte(contents), perm)).To(Succeed())
+		Expect(os.Chmod(path, perm)).To(Succeed())
+		return path
+	}
+
+	It("errors without any key", func() {
+		_, err := loadAPIKeys(stderr)
+
+		Expect(err).To(Equal(errNoAPIKey))
+	})
+
+	It("uses the credentials store", func() {
+		Expect(config.AddKey(config.CredentialsPath(), "stored-1")).To(Succeed())
+		Expect(config.AddKey(config.CredentialsPath(), "stored-2")).To(Succeed())
+
+		keys, err := loadAPIKeys(stderr)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keys).To(Equal([]string{"stored-1", "stored-2"}))
+		Expect(apiKey).To(Equal("stored-1"))
+		Expect(stderr.String()).To(BeEmpty())
+	})
+
+	It("prefers the --api-key over the credentials store", func() {
+		Expect(config.AddKey(config.CredentialsPath(), "stored-1")).To(Succeed())
+		apiKey = "given"
+
+		Expect(loadAPIKeys(stderr)).To(Equal([]string{"given"}))
+	})
+
+	It("prefers the --api-key-file over the --api-key", func() {
+		apiKey = "given"
+		apiKeyFile = writeKeys("keys", "# Team keys\nfile-1\nfile-2\n", 0600)
+
+		Expect(loadAPIKeys(stderr)).To(Equal([]string{"file-1", "file-2"}))
+		Expect(apiKey).To(Equal("file-1"))
+	})
+
+	It("warns when the key file is readable by others", func() {
+		apiKeyFile = writeKeys("keys", "file-1\n", 0644)
+
+		Expect(loadAPIKeys(stderr)).To(Equal([]string{"file-1"}))
+		Expect(stderr.String()).To(ContainSubstring("is readable by other users"))
+	})
+
+	It("errors when the key file has no keys", func() {
+		apiKeyFile = writeKeys("keys", "# Nothing yet\n", 0600)
+
+		_, err := loadAPIKeys(stderr)
+
+		Expect(err).To(MatchError(HavePrefix("no API keys in")))
+	})
+
+	It("errors when the key file can't be read", func() {
+		apiKeyFile = filepath.Join(tmpDir, "missing")
+
+		_, err := loadAPIKeys(stderr)
+
+		Expect(err).To(MatchError(HavePrefix("unable to read API keys")))
+	})
+})
+
+var _ = Describe("readAPIKey", func() {
+	It("reads the first line when not in a terminal", func() {
+		key, err := readAPIKey(strings.NewReader("secret-key\nignored\n"), false)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(key).To(Equal("secret-key"))
+	})
+})
+
+var _ = Describe("printKeys", func() {
+	It("numbers the keys, showing only their end", func() {
+		output := &bytes.Buffer{}
+
+		printKeys(output, []string{"abcdefgh1234", "ijklmnop5678"})
+
+		Expect(output.String()).To(Equal("1. ********1234\n2. ********5678\n"))
+	})
+})
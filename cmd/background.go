@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/remove-bg/go/processor"
+)
+
+// withCompositeOptions checks the background options before any prompt or
+// plan, decoding the background image once for every batch
+func withCompositeOptions(s processor.Settings) (processor.Settings, error) {
+	options, err := s.ImageSettings.CompositeOptions()
+	if err != nil {
+		return s, err
+	}
+
+	s.CompositeOptions = &options
+	return s, nil
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/processor"
+)
+
+var _ = Describe("withCompositeOptions", func() {
+	It("builds the options, decoding the background image", func() {
+		s := processor.Settings{ImageSettings: processor.ImageSettings{BgImageFile: "../fixtures/background.jpg"}}
+
+		s, err := withCompositeOptions(s)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.CompositeOptions.Background.Image).ToNot(BeNil())
+	})
+
+	It("errors for invalid background options", func() {
+		s := processor.Settings{ImageSettings: processor.ImageSettings{BgColor: "not-a-color"}}
+
+		_, err := withCompositeOptions(s)
+
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/remove-bg/go/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+var profile string
+
+// Not settings, so never read from the config files
+var ignoredFlags = map[string]bool{"help": true, "version": true, "profile": true}
+
+const sourceDefault = "default"
+const sourceFlag = "command line"
+
+var configCmd = &cobra.Command{
+	Short: "Shows the effective settings, and where each value came from",
+	Long: fmt.Sprintf(`Shows the effective settings, and where each value came from.
+
+Settings are read from %s, then %s in the current
+directory, then the --profile from either file, then the %s
+environment variable, and finally the command line.`, config.UserPath(), config.ProjectFileName, config.APIKeyEnv),
+	Use:  "config",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		return printConfig(cmd.OutOrStdout(), allFlags(RootCmd), cmd.Flags(), cfg)
+	},
+}
+
+func loadConfig() (config.Config, error) {
+	return config.Load(config.UserPath(), config.ProjectFileName, profile)
+}
+
+// applyConfig sets every flag which wasn't given on the command line from the
+// config files, profile or environment
+func applyConfig(flags *pflag.FlagSet, cfg config.Config) error {
+	var err error
+
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || ignoredFlags[flag.Name] {
+			return
+		}
+
+		setting, ok := cfg.Lookup(flag.Name)
+		if !ok {
+			return
+		}
+
+		setErr := flag.Value.Set(setting.Value)
+		if setErr != nil {
+			err = fmt.Errorf("invalid %s %q from %s: %s", flag.Name, setting.Value, setting.Source, setErr)
+		}
+	})
+
+	return err
+}
+
+// allFlags of every command, as a config file is shared between them
+func allFlags(root *cobra.Command) []*pflag.Flag {
+	byName := map[string]*pflag.Flag{}
+	add := func(flag *pflag.Flag) {
+		if _, ok := byName[flag.Name]; !ok && !ignoredFlags[flag.Name] {
+			byName[flag.Name] = flag
+		}
+	}
+
+	root.PersistentFlags().VisitAll(add)
+	root.LocalFlags().VisitAll(add)
+	for _, c := range root.Commands() {
+		c.LocalFlags().VisitAll(add)
+	}
+
+	flags := []*pflag.Flag{}
+	for _, flag := range byName {
+		flags = append(flags, flag)
+	}
+
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+
+	return flags
+}
+
+func printConfig(w io.Writer, flags []*pflag.Flag, commandLine *pflag.FlagSet, cfg config.Config) error {
+	known := map[string]bool{}
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "SETTING\tVALUE\tSOURCE")
+
+	for _, flag := range flags {
+		known[flag.Name] = true
+		value, source := flag.DefValue, sourceDefault
+
+		if given := commandLine.Lookup(flag.Name); given != nil && given.Changed {
+			value, source = given.Value.String(), sourceFlag
+		} else if setting, ok := cfg.Lookup(flag.Name); ok {
+			value, source = setting.Value, setting.Source
+		}
+
+		if flag.Name == "api-key" {
+			value = maskAPIKey(value)
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%s\n", flag.Name, value, source)
+	}
+
+	err := table.Flush()
+	if err != nil {
+		return err
+	}
+
+	unknown := []string{}
+	for _, name := range cfg.Names() {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		fmt.Fprintf(w, "\nUnknown settings (ignored): %s\n", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+// Only the end of the key is shown, to tell keys apart
+func maskAPIKey(key string) string {
+	if len(key) <= 4 {
+		return strings.Repeat("*", len(key))
+	}
+
+	return strings.Repeat("*", 8) + key[len(key)-4:]
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+}
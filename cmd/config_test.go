@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/config"
+	"github.com/spf13/pflag"
+)
+
+var _ = Describe("applyConfig", func() {
+	var (
+		flags    *pflag.FlagSet
+		size     string
+		statuses []int
+		cfg      config.Config
+	)
+
+	BeforeEach(func() {
+		flags = pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.StringVar(&size, "size", "auto", "")
+		flags.IntSliceVar(&statuses, "retry-statuses", []int{500}, "")
+
+		cfg = config.Config{Sources: []config.Source{
+			{Name: "config.yaml", Values: map[string]string{"size": "full", "retry-statuses": "502,503", "listen": ":80"}},
+		}}
+	})
+
+	It("sets flags from the config", func() {
+		Expect(flags.Parse([]string{})).To(Succeed())
+
+		Expect(applyConfig(flags, cfg)).To(Succeed())
+		Expect(size).To(Equal("full"))
+		Expect(statuses).To(Equal([]int{502, 503}))
+	})
+
+	It("prefers the command line", func() {
+		Expect(flags.Parse([]string{"--size", "preview"})).To(Succeed())
+
+		Expect(applyConfig(flags, cfg)).To(Succeed())
+		Expect(size).To(Equal("preview"))
+	})
+
+	It("errors for invalid values, saying where they came from", func() {
+		cfg.Sources[0].Values["retry-statuses"] = "often"
+
+		err := applyConfig(flags, cfg)
+
+		Expect(err).To(MatchError(HavePrefix(`invalid retry-statuses "often" from config.yaml`)))
+	})
+})
+
+var _ = Describe("printConfig", func() {
+	It("shows each setting's value and source", func() {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.String("api-key", "", "")
+		flags.String("format", "png", "")
+		flags.String("size", "auto", "")
+		flags.String("type", "", "")
+		Expect(flags.Parse([]string{"--type", "car"})).To(Succeed())
+
+		cfg := config.Config{Sources: []config.Source{
+			{Name: "config.yaml", Values: map[string]string{"size": "full", "api-key": "secret-key-1234", "colour": "red"}},
+		}}
+
+		out := &bytes.Buffer{}
+		all := []*pflag.Flag{flags.Lookup("api-key"), flags.Lookup("format"), flags.Lookup("size"), flags.Lookup("type")}
+		Expect(printConfig(out, all, flags, cfg)).To(Succeed())
+
+		Expect(out.String()).To(Equal(`SETTING  VALUE         SOURCE
+api-key  ********1234  config.yaml
+format   png           default
+size     full          config.yaml
+type     car           command line
+
+Unknown settings (ignored): colour
+`))
+	})
+})
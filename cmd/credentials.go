@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/remove-bg/go/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/AlecAivazis/survey.v1"
+	"io"
+	"os"
+	"strconv"
+)
+
+var credentialsCmd = &cobra.Command{
+	Short: "Manages the API keys kept in the credentials store",
+	Long: fmt.Sprintf(`Manages the API keys kept in %s, which is only
+readable by you. The keys are used when neither --api-key nor --api-key-file
+is given, rotating to the next key when one runs out of credits or is rate
+limited.`, config.CredentialsPath()),
+	Use: "credentials",
+}
+
+var credentialsAddCmd = &cobra.Command{
+	Short: "Adds an API key, read from a prompt or stdin so it isn't left in your shell history",
+	Use:   "add",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := readAPIKey(cmd.InOrStdin(), isTerminal(os.Stdin))
+		if err != nil {
+			return err
+		}
+
+		err = config.AddKey(config.CredentialsPath(), key)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Added %s to %s\n", maskAPIKey(key), config.CredentialsPath())
+		return nil
+	},
+}
+
+var credentialsListCmd = &cobra.Command{
+	Short: "Lists the API keys, showing only their last characters",
+	Use:   "list",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keys, err := config.ReadKeys(config.CredentialsPath())
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		printKeys(cmd.OutOrStdout(), keys)
+		return nil
+	},
+}
+
+var credentialsRemoveCmd = &cobra.Command{
+	Short: "Removes an API key, numbered as listed",
+	Use:   "remove <number>",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		number, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid key number: %s", args[0])
+		}
+
+		return config.RemoveKey(config.CredentialsPath(), number)
+	},
+}
+
+// readAPIKey prompts without echoing the key in a terminal, otherwise the
+// first line of the input is read
+func readAPIKey(in io.Reader, terminal bool) (string, error) {
+	key := ""
+
+	if terminal {
+		err := survey.AskOne(&survey.Password{Message: "API key:"}, &key, nil)
+		return key, err
+	}
+
+	scanner := bufio.NewScanner(in)
+	if scanner.Scan() {
+		key = scanner.Text()
+	}
+
+	return key, scanner.Err()
+}
+
+func printKeys(w io.Writer, keys []string) {
+	for i, key := range keys {
+		fmt.Fprintf(w, "%d. %s\n", i+1, maskAPIKey(key))
+	}
+}
+
+func init() {
+	credentialsCmd.AddCommand(credentialsAddCmd, credentialsListCmd, credentialsRemoveCmd)
+	RootCmd.AddCommand(credentialsCmd)
+}
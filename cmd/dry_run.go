@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/remove-bg/go/processor"
+	"io"
+	"text/tabwriter"
+)
+
+func printPlan(w io.Writer, plan processor.Plan) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, image := range plan.Images {
+		fmt.Fprintf(table, "%s\t%s\t-> %s\n", image.Action, image.Input, image.Output)
+	}
+
+	err := table.Flush()
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "\n%d to process, %d to skip, %d to overwrite (estimated %g credits)\n",
+		plan.Process, plan.Skip, plan.Overwrite, plan.EstimatedCredits)
+
+	return err
+}
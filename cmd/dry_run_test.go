@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"bytes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/processor"
+)
+
+var _ = Describe("printPlan", func() {
+	It("prints each image and the totals", func() {
+		plan := processor.Plan{
+			Images: []processor.PlannedImage{
+				{Input: "dir/image1.jpg", Output: "out/image1.png", Action: processor.PlanProcess},
+				{Input: "dir/image2.jpg", Output: "out/image2.png", Action: processor.PlanSkip},
+			},
+			Process:          1,
+			Skip:             1,
+			EstimatedCredits: 1,
+		}
+		out := &bytes.Buffer{}
+
+		Expect(printPlan(out, plan)).To(Succeed())
+
+		Expect(out.String()).To(Equal(
+			"process  dir/image1.jpg  -> out/image1.png\n" +
+				"skip     dir/image2.jpg  -> out/image2.png\n" +
+				"\n1 to process, 1 to skip, 0 to overwrite (estimated 1 credits)\n"))
+	})
+})
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/remove-bg/go/client"
+	"github.com/remove-bg/go/processor"
+	"github.com/remove-bg/go/storage"
+)
+
+// Exit codes, so scripts can tell why a batch didn't complete
+const (
+	ExitOK             = 0
+	ExitError          = 1
+	ExitPartialFailure = 2
+	ExitRateLimited    = 3
+	ExitAuthFailed     = 4
+	ExitCancelled      = 5
+)
+
+// ExitCodeError is returned when the command should exit with a specific code
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
+// ExitCode for the error returned by executing the command
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var exitErr *ExitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	return ExitError
+}
+
+// summaryError is nil when every image was processed or skipped
+func summaryError(summary processor.Summary) error {
+	switch {
+	case summary.AuthFailed:
+		return &ExitCodeError{Code: ExitAuthFailed, Err: errors.New("the API key was rejected")}
+	case summary.RateLimited:
+		return &ExitCodeError{Code: ExitRateLimited, Err: errors.New("the API rate limit was exceeded")}
+	case summary.Cancelled:
+		return &ExitCodeError{Code: ExitCancelled, Err: errors.New("the batch was cancelled")}
+	case summary.Failed > 0 || summary.Remaining() > 0:
+		return &ExitCodeError{
+			Code: ExitPartialFailure,
+			Err:  fmt.Errorf("%d of %d images were not processed", summary.Failed+summary.Remaining(), summary.Total),
+		}
+	default:
+		return nil
+	}
+}
+
+// requestExitError gives API errors the same exit codes as a batch
+func requestExitError(err error) error {
+	clientErr, ok := err.(*client.RequestError)
+
+	switch {
+	case ok && clientErr.AuthenticationFailed():
+		return &ExitCodeError{Code: ExitAuthFailed, Err: err}
+	case ok && clientErr.RateLimitExceeded():
+		return &ExitCodeError{Code: ExitRateLimited, Err: err}
+	default:
+		return err
+	}
+}
+
+func writeReport(s storage.StorageInterface, path string, summary processor.Summary) error {
+	report, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return s.Write(path, append(report, '\n'))
+}
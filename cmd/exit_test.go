@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/processor"
+	"github.com/remove-bg/go/storage/storagefakes"
+)
+
+var _ = Describe("exit codes", func() {
+	It("succeeds when every image was processed or skipped", func() {
+		summary := processor.Summary{Total: 2, Processed: 1, Skipped: 1}
+
+		Expect(ExitCode(summaryError(summary))).To(Equal(ExitOK))
+	})
+
+	It("reports partial failures", func() {
+		summary := processor.Summary{Total: 3, Processed: 1, Failed: 1}
+
+		err := summaryError(summary)
+
+		Expect(ExitCode(err)).To(Equal(ExitPartialFailure))
+		Expect(err).To(MatchError("2 of 3 images were not processed"))
+	})
+
+	It("prefers an authentication failure over the other reasons", func() {
+		summary := processor.Summary{Total: 2, Failed: 1, RateLimited: true, AuthFailed: true}
+
+		Expect(ExitCode(summaryError(summary))).To(Equal(ExitAuthFailed))
+	})
+
+	It("reports rate limiting", func() {
+		summary := processor.Summary{Total: 2, Failed: 1, RateLimited: true, Cancelled: true}
+
+		Expect(ExitCode(summaryError(summary))).To(Equal(ExitRateLimited))
+	})
+
+	It("reports cancelled batches", func() {
+		summary := processor.Summary{Total: 2, Processed: 1, Cancelled: true}
+
+		Expect(ExitCode(summaryError(summary))).To(Equal(ExitCancelled))
+	})
+
+	It("uses the generic code for other errors", func() {
+		Expect(ExitCode(errors.New("boom"))).To(Equal(ExitError))
+	})
+})
+
+var _ = Describe("writeReport", func() {
+	It("writes the summary as JSON", func() {
+		fakeStorage := &storagefakes.FakeStorageInterface{}
+		summary := processor.Summary{Total: 1, Processed: 1, Credits: 1}
+
+		Expect(writeReport(fakeStorage, "summary.json", summary)).To(Succeed())
+
+		Expect(fakeStorage.WriteCallCount()).To(Equal(1))
+		path, data := fakeStorage.WriteArgsForCall(0)
+		Expect(path).To(Equal("summary.json"))
+		Expect(data).To(MatchJSON(`{
+			"total": 1, "processed": 1, "skipped": 0, "failed": 0, "remaining": 0,
+			"credits": 1, "duplicates": 0, "credits_saved": 0,
+			"duration_seconds": 0, "cancelled": false,
+			"budget_exceeded": false, "rate_limited": false, "auth_failed": false,
+			"errors": []
+		}`))
+	})
+})
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/remove-bg/go/processor"
+	"path/filepath"
+)
+
+// newJournal kept with the outputs. Without an output directory they're next to
+// their inputs, so there's nowhere to keep it unless resuming.
+func newJournal() processor.JournalInterface {
+	if len(outputDirectory) == 0 && !resume {
+		return nil
+	}
+
+	return processor.NewFileJournal(filepath.Join(outputDirectory, processor.JournalFileName))
+}
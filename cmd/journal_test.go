@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/processor"
+	"path/filepath"
+)
+
+var _ = Describe("newJournal", func() {
+	AfterEach(func() {
+		outputDirectory, resume = "", false
+	})
+
+	It("is kept in the output directory", func() {
+		outputDirectory = "processed"
+
+		Expect(newJournal().(processor.FileJournal).Path).To(Equal(filepath.Join("processed", ".removebg-journal.jsonl")))
+	})
+
+	It("isn't kept without an output directory", func() {
+		Expect(newJournal()).To(BeNil())
+	})
+
+	It("is kept in the current directory when resuming without an output directory", func() {
+		resume = true
+
+		Expect(newJournal().(processor.FileJournal).Path).To(Equal(".removebg-journal.jsonl"))
+	})
+})
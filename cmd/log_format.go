@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/mattn/go-isatty"
+	"github.com/remove-bg/go/processor"
+	"io"
+	"os"
+)
+
+const (
+	logFormatAuto     = "auto"
+	logFormatText     = "text"
+	logFormatJSON     = "json"
+	logFormatProgress = "progress"
+)
+
+// The progress bar is used by default when stdout is a terminal
+func newNotifier(format string, w io.Writer, terminal bool) (processor.NotifierInterface, error) {
+	if format == logFormatAuto {
+		format = logFormatText
+		if terminal {
+			format = logFormatProgress
+		}
+	}
+
+	switch format {
+	case logFormatText:
+		return processor.NewNotifier(), nil
+	case logFormatJSON:
+		return processor.NewJSONNotifier(w), nil
+	case logFormatProgress:
+		return processor.NewProgressNotifier(w), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q (expected %s, %s, %s or %s)",
+			format, logFormatAuto, logFormatText, logFormatJSON, logFormatProgress)
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// finishNotifier ends any output the notifier left open, such as the
+// progress bar
+func finishNotifier(notifier processor.NotifierInterface) {
+	if n, ok := notifier.(interface{ Finish() }); ok {
+		n.Finish()
+	}
+}
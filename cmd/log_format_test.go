@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/processor"
+)
+
+var _ = Describe("newNotifier", func() {
+	It("builds the text notifier", func() {
+		notifier, err := newNotifier("text", &bytes.Buffer{}, true)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(notifier).To(BeAssignableToTypeOf(processor.Notifier{}))
+	})
+
+	It("builds the JSON notifier", func() {
+		notifier, err := newNotifier("json", &bytes.Buffer{}, false)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(notifier).To(BeAssignableToTypeOf(processor.JSONNotifier{}))
+	})
+
+	It("builds the progress notifier", func() {
+		notifier, err := newNotifier("progress", &bytes.Buffer{}, false)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(notifier).To(BeAssignableToTypeOf(&processor.ProgressNotifier{}))
+	})
+
+	Context("auto", func() {
+		It("uses the progress bar in a terminal", func() {
+			notifier, err := newNotifier("auto", &bytes.Buffer{}, true)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(notifier).To(BeAssignableToTypeOf(&processor.ProgressNotifier{}))
+		})
+
+		It("uses the text notifier otherwise", func() {
+			notifier, err := newNotifier("auto", &bytes.Buffer{}, false)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(notifier).To(BeAssignableToTypeOf(processor.Notifier{}))
+		})
+	})
+
+	It("rejects unknown formats", func() {
+		_, err := newNotifier("xml", &bytes.Buffer{}, false)
+
+		Expect(err).To(MatchError(`unknown log format "xml" (expected auto, text, json or progress)`))
+	})
+})
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"github.com/remove-bg/go/processor"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var renderCmd = &cobra.Command{
+	Short: "Re-renders images from cached masks, without calling the API",
+	Long: `Re-renders images from the masks cached by --cache-masks, using the original
+images. The --size, --type, --channels and --extra-api-options must match
+those the images were processed with, while the background and format can
+be changed. No credits are used.`,
+	Use:  "render <file>...",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := withCompositeOptions(processorSettings())
+		if err != nil {
+			return err
+		}
+
+		notifier, err := newNotifier(logFormat, cmd.OutOrStdout(), isTerminal(os.Stdout))
+		if err != nil {
+			return err
+		}
+
+		p := processor.NewProcessor("", cmd.Root().Version)
+		p.Notifier = notifier
+		p.MaskCache = processor.NewFileMaskCache(maskCacheDirectory)
+
+		summary := p.Render(cmd.Context(), args, s)
+		finishNotifier(notifier)
+
+		err = summaryError(summary)
+		if err != nil {
+			cmd.SilenceUsage = true
+		}
+
+		return err
+	},
+}
+
+func init() {
+	addProcessingFlags(renderCmd.Flags())
+	RootCmd.AddCommand(renderCmd)
+}
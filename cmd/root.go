@@ -3,10 +3,15 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"github.com/remove-bg/go/client"
+	"github.com/remove-bg/go/composite"
 	"github.com/remove-bg/go/processor"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"net/http"
 	"os"
 	"strings"
+	"time"
 )
 
 const defaultLargeBatchSize = 50
@@ -14,6 +19,16 @@ const defaultLargeBatchSize = 50
 var (
 	apiKey                    string
 	confirmBatchOver          int
+	concurrency               int
+	maxCredits                float64
+	rateLimitMaxWait          time.Duration
+	rateLimitMaxRetries       int
+	retryAttempts             int
+	retryBaseDelay            time.Duration
+	retryMaxDelay             time.Duration
+	retryJitter               float64
+	retryStatuses             []int
+	retryNetworkErrors        bool
 	outputDirectory           string
 	reprocessExisting         bool
 	skipPngFormatOptimization bool
@@ -23,66 +38,231 @@ var (
 	imageChannels             string
 	bgColor                   string
 	bgImageFile               string
+	bgMode                    string
 	extraApiOptions           string
+	urlList                   string
+	dryRun                    bool
+	resume                    bool
+	reportPath                string
+	logFormat                 string
+	cacheMasks                bool
+	dedupe                    bool
+	maskCacheDirectory        string
 )
 
 // RootCmd is the entry point of command-line execution
 var RootCmd = &cobra.Command{
 	Short: "Remove image background - 100% automatically",
-	Use:   "removebg <file>...",
-	Args:  cobra.MinimumNArgs(1),
+	Use:   "removebg <file or URL>... | -",
+	Args:  cobra.ArbitraryArgs, // Not subcommands, checked once the --url-list is read
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err == nil {
+			err = applyConfig(cmd.Flags(), cfg)
+		}
+
+		if err != nil {
+			cmd.SilenceUsage = true
+		}
+
+		return err
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if len(apiKey) == 0 {
-			return errors.New("API key must be specified")
+		var keys []string
+		if !dryRun {
+			var err error
+			keys, err = loadAPIKeys(cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(urlList) > 0 {
+			urls, err := readURLListFile(urlList)
+			if err != nil {
+				return err
+			}
+
+			args = append(args, urls...)
 		}
 
 		if len(args) == 0 {
 			return errors.New("please specify one or more files")
 		}
 
+		stdin, err := readsStdin(args)
+		if err != nil {
+			return err
+		}
+
+		if stdin {
+			s := processorSettings()
+			c := client.Client{Version: cmd.Version, HTTPClient: http.Client{}}
+
+			return processStdin(cmd.Context(), c, composite.New(), cmd.InOrStdin(), cmd.OutOrStdout(), s)
+		}
+
+		s, err := withCompositeOptions(processorSettings())
+		if err != nil {
+			return err
+		}
+
+		s.LargeBatchConfirmThreshold = confirmBatchOver
+		s.Concurrency = concurrency
+		s.Resume = resume
+		s.MaxCredits = maxCredits
+
+		notifier, err := newNotifier(logFormat, cmd.OutOrStdout(), isTerminal(os.Stdout))
+		if err != nil {
+			return err
+		}
+
 		p := processor.NewProcessor(apiKey, cmd.Version)
-		s := processor.Settings{
-			OutputDirectory:            outputDirectory,
-			ReprocessExisting:          reprocessExisting,
-			SkipPngFormatOptimization:  skipPngFormatOptimization,
-			LargeBatchConfirmThreshold: confirmBatchOver,
-			ImageSettings: processor.ImageSettings{
-				Size:            imageSize,
-				Type:            imageType,
-				Channels:        imageChannels,
-				BgColor:         bgColor,
-				BgImageFile:     bgImageFile,
-				OutputFormat:    strings.ToLower(imageFormat),
-				ExtraApiOptions: extraApiOptions,
-			},
-		}
-
-		p.Process(args, s)
+		p.Notifier = notifier
+		p.Keys = newKeyPool(keys)
+		p.Journal = newJournal()
+		p.MaskCache = newMaskCache()
 
-		return nil
+		if dryRun {
+			plan, err := p.Plan(args, s)
+			if err != nil {
+				return err
+			}
+
+			return printPlan(cmd.OutOrStdout(), plan)
+		}
+
+		summary := p.Process(cmd.Context(), args, s)
+		finishNotifier(notifier)
+
+		// Keep stdout to one JSON event per line
+		status := cmd.OutOrStdout()
+		if logFormat == logFormatJSON {
+			status = cmd.ErrOrStderr()
+		}
+
+		if summary.Duplicates > 0 {
+			fmt.Fprintf(status, "Copied %d duplicate images, saving %g credits\n", summary.Duplicates, summary.CreditsSaved)
+		}
+
+		if summary.BudgetExceeded {
+			fmt.Fprintf(status, "Stopped: credit budget of %g reached (%g credits used)\n", maxCredits, summary.Credits)
+		}
+
+		if summary.Cancelled {
+			fmt.Fprintf(status, "Cancelled: %d processed, %d skipped, %d failed, %d not started (of %d images)\n",
+				summary.Processed, summary.Skipped, summary.Failed, summary.Remaining(), summary.Total)
+		}
+
+		if len(reportPath) > 0 {
+			err := writeReport(p.Storage, reportPath, summary)
+			if err != nil {
+				return fmt.Errorf("unable to write report: %s", err)
+			}
+		}
+
+		err = summaryError(summary)
+		if err != nil {
+			// The images' own errors have already been shown
+			cmd.SilenceUsage = true
+		}
+
+		return err
 	},
 }
 
+// processorSettings from the flags shared by every processing command
+func processorSettings() processor.Settings {
+	return processor.Settings{
+		OutputDirectory:           outputDirectory,
+		ReprocessExisting:         reprocessExisting,
+		SkipPngFormatOptimization: skipPngFormatOptimization,
+		Dedupe:                    dedupe,
+		RateLimit: processor.RateLimitSettings{
+			MaxWait:    rateLimitMaxWait,
+			MaxRetries: rateLimitMaxRetries,
+		},
+		Retry: processor.RetryPolicy{
+			Attempts:           retryAttempts,
+			BaseDelay:          retryBaseDelay,
+			MaxDelay:           retryMaxDelay,
+			Jitter:             retryJitter,
+			RetryableStatuses:  retryStatuses,
+			RetryNetworkErrors: retryNetworkErrors,
+		},
+		ImageSettings: processor.ImageSettings{
+			Size:            imageSize,
+			Type:            imageType,
+			Channels:        imageChannels,
+			BgColor:         bgColor,
+			BgImageFile:     bgImageFile,
+			BgMode:          bgMode,
+			OutputFormat:    strings.ToLower(imageFormat),
+			ExtraApiOptions: extraApiOptions,
+		},
+	}
+}
+
+// newKeyPool when there's more than one key to rotate between
+func newKeyPool(keys []string) *processor.KeyPool {
+	if len(keys) < 2 {
+		return nil
+	}
+
+	return processor.NewKeyPool(keys)
+}
+
+// newMaskCache when --cache-masks is set, as the processor skips a nil cache
+func newMaskCache() processor.MaskCacheInterface {
+	if !cacheMasks {
+		return nil
+	}
+
+	return processor.NewFileMaskCache(maskCacheDirectory)
+}
+
 func ConfigureVersion(version string, commit string) {
 	RootCmd.Version = version
 	RootCmd.SetVersionTemplate(fmt.Sprintf("%s\n%s\n", version, commit))
 }
 
 func init() {
-	RootCmd.Flags().StringVar(&apiKey, "api-key", "", "API key (required) or set REMOVE_BG_API_KEY environment variable")
-	RootCmd.Flags().StringVar(&outputDirectory, "output-directory", "", "Output directory")
-	RootCmd.Flags().BoolVar(&reprocessExisting, "reprocess-existing", false, "Reprocess and overwrite any already processed images")
-	RootCmd.Flags().BoolVar(&skipPngFormatOptimization, "skip-png-format-optimization", false, "Skip optimizing PNG format as ZIP to save bandwidth (default false)")
+	RootCmd.PersistentFlags().StringVar(&apiKey, "api-key", "", "API key (required) or set REMOVE_BG_API_KEY environment variable")
+	RootCmd.PersistentFlags().StringVar(&apiKeyFile, "api-key-file", "", "File of API keys, one per line, rotating to the next when one runs out of credits or is rate limited")
+	RootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Named profile of settings from the config files")
+	RootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print what would be processed, skipped or overwritten without calling the API")
+	RootCmd.Flags().BoolVar(&resume, "resume", false, "Resume a previous batch, skipping images its journal recorded as processed")
+	RootCmd.Flags().StringVar(&reportPath, "report", "", "Write a JSON summary of the batch to this file")
 	RootCmd.Flags().IntVar(&confirmBatchOver, "confirm-batch-over", defaultLargeBatchSize, "Confirm any batches over this size (-1 to disable)")
-	RootCmd.Flags().StringVar(&imageSize, "size", "auto", "Image size")
-	RootCmd.Flags().StringVar(&imageType, "type", "", "Image type")
-	RootCmd.Flags().StringVar(&imageFormat, "format", "png", "Image format")
-	RootCmd.Flags().StringVar(&imageChannels, "channels", "", "Image channels")
-	RootCmd.Flags().StringVar(&bgColor, "bg-color", "", "Image background color")
-	RootCmd.Flags().StringVar(&bgImageFile, "bg-image-file", "", "Adds a background image from a file")
-	RootCmd.Flags().StringVar(&extraApiOptions, "extra-api-options", "", "Extra options to forward to the API (format: 'option1=val1&option2=val2')")
-
-	if len(apiKey) == 0 {
-		apiKey = os.Getenv("REMOVE_BG_API_KEY")
-	}
+	RootCmd.Flags().IntVar(&concurrency, "concurrency", 1, "Number of images to process in parallel")
+	RootCmd.Flags().Float64Var(&maxCredits, "max-credits", 0, "Stop processing before the credits charged would exceed this budget (0 for no limit)")
+	RootCmd.Flags().StringVar(&urlList, "url-list", "", "File of image URLs to process, one per line")
+	addProcessingFlags(RootCmd.Flags())
+}
+
+// addProcessingFlags adds the flags read by processorSettings
+func addProcessingFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&outputDirectory, "output-directory", "", "Output directory")
+	flags.StringVar(&logFormat, "log-format", logFormatAuto, "Log format: text, json (one event per line), progress, or auto to show a progress bar in a terminal")
+	flags.BoolVar(&reprocessExisting, "reprocess-existing", false, "Reprocess and overwrite any already processed images")
+	flags.BoolVar(&dedupe, "dedupe", true, "Process byte-identical images once, copying the output to the others")
+	flags.BoolVar(&skipPngFormatOptimization, "skip-png-format-optimization", false, "Skip optimizing PNG format as ZIP to save bandwidth (default false)")
+	flags.DurationVar(&rateLimitMaxWait, "rate-limit-max-wait", 5*time.Minute, "Longest wait before retrying when the rate limit is exceeded")
+	flags.IntVar(&rateLimitMaxRetries, "rate-limit-max-retries", 5, "Retries per image when the rate limit is exceeded (0 to stop immediately)")
+	flags.IntVar(&retryAttempts, "retry-attempts", 3, "Attempts per image for server and network errors (1 to disable retries)")
+	flags.DurationVar(&retryBaseDelay, "retry-base-delay", time.Second, "Delay before the first retry, doubling on each subsequent retry")
+	flags.DurationVar(&retryMaxDelay, "retry-max-delay", 30*time.Second, "Longest delay between retries")
+	flags.Float64Var(&retryJitter, "retry-jitter", 0.2, "Random extra delay as a fraction of the retry delay")
+	flags.IntSliceVar(&retryStatuses, "retry-statuses", processor.DefaultRetryableStatuses, "HTTP statuses to retry")
+	flags.BoolVar(&retryNetworkErrors, "retry-network-errors", true, "Retry network errors")
+	flags.StringVar(&imageSize, "size", "auto", "Image size")
+	flags.StringVar(&imageType, "type", "", "Image type")
+	flags.StringVar(&imageFormat, "format", "png", "Image format")
+	flags.StringVar(&imageChannels, "channels", "", "Image channels")
+	flags.StringVar(&bgColor, "bg-color", "", "Background color, applied locally (e.g. 81d4fa, fff or green)")
+	flags.StringVar(&bgImageFile, "bg-image-file", "", "Background image file, applied locally without being uploaded")
+	flags.StringVar(&bgMode, "bg-mode", string(composite.BackgroundFill), "How the background image is sized: fit, fill, center or tile")
+	flags.StringVar(&extraApiOptions, "extra-api-options", "", "Extra options to forward to the API (format: 'option1=val1&option2=val2')")
+	flags.BoolVar(&cacheMasks, "cache-masks", false, "Cache the mask of each image, so it can be re-rendered later without using credits")
+	flags.StringVar(&maskCacheDirectory, "mask-cache-dir", processor.DefaultMaskCacheDirectory(), "Directory of cached masks")
 }
@@ -4,6 +4,8 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/remove-bg/go/cmd"
+	"io/ioutil"
+	"os"
 )
 
 var _ = Describe("ConfigureVersion", func() {
@@ -13,3 +15,42 @@ var _ = Describe("ConfigureVersion", func() {
 		Expect(RootCmd.Version).To(Equal("x.y.z"))
 	})
 })
+
+var _ = Describe("RootCmd", func() {
+	var (
+		tmpDir            string
+		workingDir        string
+		previousConfigDir string
+	)
+
+	// Neither the user's config directory nor a .removebg.yaml in the working
+	// directory should change the outcome
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "root-spec")
+		Expect(err).ToNot(HaveOccurred())
+
+		workingDir, err = os.Getwd()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.Chdir(tmpDir)).To(Succeed())
+
+		previousConfigDir = os.Getenv("XDG_CONFIG_HOME")
+		os.Setenv("XDG_CONFIG_HOME", tmpDir)
+	})
+
+	AfterEach(func() {
+		os.Setenv("XDG_CONFIG_HOME", previousConfigDir)
+		Expect(os.Chdir(workingDir)).To(Succeed())
+		os.RemoveAll(tmpDir)
+	})
+
+	It("accepts files as arguments rather than subcommands", func() {
+		RootCmd.SetArgs([]string{"--api-key", "", "/nonexistent/a.jpg"})
+		RootCmd.SetOut(ioutil.Discard)
+		RootCmd.SetErr(ioutil.Discard)
+
+		err := RootCmd.Execute()
+
+		Expect(err).To(MatchError("API key must be specified"))
+	})
+})
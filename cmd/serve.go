@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/remove-bg/go/server"
+	"github.com/spf13/cobra"
+	"net"
+	"net/http"
+	"time"
+)
+
+var (
+	serveListen      string
+	serveAccessKeys  []string
+	serveConcurrency int
+	serveMaxQueued   int
+)
+
+// How long in-flight requests have to finish when shutting down
+const serveShutdownTimeout = 30 * time.Second
+
+var serveCmd = &cobra.Command{
+	Short: "Runs an HTTP server which removes the background of posted images",
+	Use:   "serve",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := requireAccessKeys(serveListen, serveAccessKeys)
+		if err != nil {
+			return err
+		}
+
+		_, err = loadAPIKeys(cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+
+		s := server.New(apiKey, cmd.Root().Version)
+		s.AccessKeys = serveAccessKeys
+		s.Limiter = server.NewLimiter(serveConcurrency, serveMaxQueued)
+
+		httpServer := &http.Server{Addr: serveListen, Handler: s}
+
+		errs := make(chan error, 1)
+		go func() {
+			errs <- httpServer.ListenAndServe()
+		}()
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Listening on %s, POST images to %s\n", serveListen, server.Endpoint)
+
+		select {
+		case err := <-errs:
+			return err
+		case <-cmd.Context().Done():
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+
+		return httpServer.Shutdown(ctx)
+	},
+}
+
+// requireAccessKeys unless only local clients can connect, as anyone else who
+// can reach the server would be able to spend the API key's credits
+func requireAccessKeys(listen string, accessKeys []string) error {
+	if len(accessKeys) > 0 {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return nil
+	}
+
+	return fmt.Errorf("an --access-key is required to listen on %s, as any client could use your credits", listen)
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:8080", "Address to listen on (an --access-key is required unless it's loopback)")
+	serveCmd.Flags().StringSliceVar(&serveAccessKeys, "access-key", nil, "Key clients must send as X-Api-Key (repeat for several clients, none to allow any local client)")
+	serveCmd.Flags().IntVar(&serveConcurrency, "concurrency", 4, "Number of images to process in parallel")
+	serveCmd.Flags().IntVar(&serveMaxQueued, "max-queued", 100, "Requests to queue once all are in progress, before responding 503")
+	RootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("requireAccessKeys", func() {
+	It("allows any local client without access keys", func() {
+		Expect(requireAccessKeys("127.0.0.1:8080", nil)).To(Succeed())
+		Expect(requireAccessKeys("[::1]:8080", nil)).To(Succeed())
+		Expect(requireAccessKeys("localhost:8080", nil)).To(Succeed())
+	})
+
+	It("requires access keys to listen on other interfaces", func() {
+		Expect(requireAccessKeys(":8080", nil)).To(MatchError(HavePrefix("an --access-key is required to listen on :8080")))
+		Expect(requireAccessKeys("0.0.0.0:8080", nil)).ToNot(Succeed())
+		Expect(requireAccessKeys("192.168.1.10:8080", nil)).ToNot(Succeed())
+	})
+
+	It("allows any interface with access keys", func() {
+		Expect(requireAccessKeys(":8080", []string{"team-a"})).To(Succeed())
+	})
+
+	It("errors for invalid addresses", func() {
+		Expect(requireAccessKeys("8080", nil)).ToNot(Succeed())
+	})
+})
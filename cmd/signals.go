@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// CancelOnSignal cancels the context on the first SIGINT or SIGTERM, so
+// in-flight work can finish or clean up. A second signal exits immediately.
+func CancelOnSignal(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-signals:
+			fmt.Fprintln(os.Stderr, "Cancelling, press Ctrl-C again to exit immediately")
+			cancel()
+		case <-ctx.Done():
+			signal.Stop(signals)
+			return
+		}
+
+		<-signals
+		os.Exit(130)
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
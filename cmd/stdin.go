@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/remove-bg/go/client"
+	"github.com/remove-bg/go/composite"
+	"github.com/remove-bg/go/processor"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdinArg reads the image from stdin and writes the result to stdout
+const stdinArg = "-"
+
+// Only a hint for the API, as there's no file name to send
+const stdinFileName = "stdin"
+
+var errStdoutTerminal = errors.New("refusing to write the image to a terminal, redirect stdout to a file or pipe")
+
+var errStdinDryRun = errors.New("--dry-run can't be used when reading the image from stdin")
+
+// readsStdin is true when the only input is stdin, as long as the result can
+// be written to stdout. There's nothing to plan, so a dry run is an error
+// rather than sending the image.
+func readsStdin(args []string) (bool, error) {
+	if len(args) != 1 || args[0] != stdinArg {
+		return false, nil
+	}
+
+	if dryRun {
+		return true, errStdinDryRun
+	}
+
+	if isTerminal(os.Stdout) {
+		return true, errStdoutTerminal
+	}
+
+	return true, nil
+}
+
+// processStdin sends the image to the API and writes the result, compositing
+// ZIP responses in memory
+func processStdin(ctx context.Context, c client.ClientInterface, compositor composite.CompositorInterface, in io.Reader, out io.Writer, settings processor.Settings) error {
+	options, err := settings.ImageSettings.CompositeOptions()
+	if err != nil {
+		return err
+	}
+
+	result, err := c.RemoveFromReader(ctx, in, stdinFileName, apiKey, settings.APIParams())
+	if err != nil {
+		return requestExitError(err)
+	}
+
+	if strings.Contains(result.ContentType, processor.MimeZip) {
+		return compositor.Composite(ctx, bytes.NewReader(result.Data), int64(len(result.Data)), out, options)
+	}
+
+	_, err = out.Write(result.Data)
+	return err
+}
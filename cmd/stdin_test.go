@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/client"
+	"github.com/remove-bg/go/client/clientfakes"
+	"github.com/remove-bg/go/composite"
+	"github.com/remove-bg/go/processor"
+	"image/png"
+	"io/ioutil"
+)
+
+var _ = Describe("processStdin", func() {
+	var (
+		fakeClient *clientfakes.FakeClientInterface
+		settings   processor.Settings
+		out        *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		fakeClient = &clientfakes.FakeClientInterface{}
+		settings = processor.Settings{
+			ImageSettings: processor.ImageSettings{Size: "preview", OutputFormat: "png"},
+		}
+		out = &bytes.Buffer{}
+	})
+
+	It("sends the image and writes the result", func() {
+		fakeClient.RemoveFromReaderReturns(client.Result{Data: []byte("processed"), ContentType: "image/jpeg"}, nil)
+
+		err := processStdin(context.Background(), fakeClient, composite.New(), bytes.NewBufferString("original"), out, settings)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(Equal("processed"))
+
+		_, image, _, _, params := fakeClient.RemoveFromReaderArgsForCall(0)
+		Expect(ioutil.ReadAll(image)).To(Equal([]byte("original")))
+		Expect(params).To(Equal(map[string]string{"size": "preview", "format": "zip"}))
+	})
+
+	It("composites ZIP responses", func() {
+		zipData, err := ioutil.ReadFile("../fixtures/zip/example-cat.zip")
+		Expect(err).ToNot(HaveOccurred())
+		fakeClient.RemoveFromReaderReturns(client.Result{Data: zipData, ContentType: "application/zip"}, nil)
+
+		err = processStdin(context.Background(), fakeClient, composite.New(), &bytes.Buffer{}, out, settings)
+
+		Expect(err).ToNot(HaveOccurred())
+		_, err = png.Decode(out)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("gives API errors their exit code", func() {
+		fakeClient.RemoveFromReaderReturns(client.Result{}, &client.RequestError{StatusCode: 403, Err: errors.New("API Key invalid")})
+
+		err := processStdin(context.Background(), fakeClient, composite.New(), &bytes.Buffer{}, out, settings)
+
+		Expect(ExitCode(err)).To(Equal(ExitAuthFailed))
+		Expect(out.Len()).To(BeZero())
+	})
+})
+
+var _ = Describe("readsStdin", func() {
+	AfterEach(func() {
+		dryRun = false
+	})
+
+	It("is true when the only input is stdin", func() {
+		Expect(readsStdin([]string{"-"})).To(BeTrue())
+		Expect(readsStdin([]string{"-", "a.jpg"})).To(BeFalse())
+		Expect(readsStdin([]string{"a.jpg"})).To(BeFalse())
+	})
+
+	It("rejects a dry run rather than sending the image", func() {
+		dryRun = true
+
+		_, err := readsStdin([]string{"-"})
+
+		Expect(err).To(Equal(errStdinDryRun))
+	})
+})
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// readURLList reads one image URL per line, ignoring blank lines and # comments
+func readURLList(r io.Reader) ([]string, error) {
+	urls := []string{}
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		urls = append(urls, line)
+	}
+
+	return urls, scanner.Err()
+}
+
+func readURLListFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return readURLList(file)
+}
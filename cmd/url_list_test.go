@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"strings"
+)
+
+var _ = Describe("readURLList", func() {
+	It("reads one URL per line", func() {
+		input := "https://cdn.example/a.jpg\n  https://cdn.example/b.jpg  \n"
+
+		urls, err := readURLList(strings.NewReader(input))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(urls).To(Equal([]string{"https://cdn.example/a.jpg", "https://cdn.example/b.jpg"}))
+	})
+
+	It("ignores blank lines and comments", func() {
+		input := "# Spring catalogue\n\nhttps://cdn.example/a.jpg\n"
+
+		urls, err := readURLList(strings.NewReader(input))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(urls).To(Equal([]string{"https://cdn.example/a.jpg"}))
+	})
+})
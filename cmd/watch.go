@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/remove-bg/go/processor"
+	"github.com/remove-bg/go/watch"
+	"github.com/spf13/cobra"
+	"path/filepath"
+	"time"
+)
+
+var (
+	watchSettle        time.Duration
+	watchMoveOriginals bool
+)
+
+const (
+	watchOutputDirectory    = "output"
+	watchProcessedDirectory = "processed"
+	watchFailedDirectory    = "failed"
+)
+
+var watchCmd = &cobra.Command{
+	Short: "Processes images as they're added to a directory",
+	Use:   "watch <directory>",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keys, err := loadAPIKeys(cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+
+		dir := args[0]
+
+		// Outputs mustn't land in the watched directory, or they'd be processed too
+		s, err := withCompositeOptions(processorSettings())
+		if err != nil {
+			return err
+		}
+
+		if len(s.OutputDirectory) == 0 {
+			s.OutputDirectory = filepath.Join(dir, watchOutputDirectory)
+		}
+
+		s.LargeBatchConfirmThreshold = -1
+
+		notifier, err := newNotifier(logFormat, cmd.OutOrStdout(), false)
+		if err != nil {
+			return err
+		}
+
+		p := processor.NewProcessor(apiKey, cmd.Root().Version)
+		p.Notifier = notifier
+		p.Keys = newKeyPool(keys)
+		p.Journal = processor.NewFileJournal(filepath.Join(s.OutputDirectory, processor.JournalFileName))
+		p.MaskCache = newMaskCache()
+		p.DedupeIndex = processor.NewDedupeIndex() // Each image is a separate call to Process
+
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+
+		var stopErr error
+		w := watch.Watcher{Directory: dir, Settle: watchSettle}
+
+		err = w.Run(ctx, func(path string) {
+			summary := p.Process(ctx, []string{path}, s)
+
+			if watchMoveOriginals {
+				_, err := moveOriginal(path, summary)
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Unable to move %s: %s\n", path, err)
+				}
+			}
+
+			// Every later image would be rejected too
+			if summary.AuthFailed {
+				stopErr = summaryError(summary)
+				cancel()
+			}
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if stopErr != nil {
+			cmd.SilenceUsage = true
+		}
+
+		return stopErr
+	},
+}
+
+// moveOriginal into the processed/ or failed/ subdirectory, leaving images
+// which weren't attempted for the next run
+func moveOriginal(path string, summary processor.Summary) (string, error) {
+	switch {
+	case summary.Failed > 0:
+		return watch.MoveTo(path, watchFailedDirectory)
+	case summary.Processed > 0 || summary.Skipped > 0:
+		return watch.MoveTo(path, watchProcessedDirectory)
+	default:
+		return path, nil
+	}
+}
+
+func init() {
+	watchCmd.Flags().DurationVar(&watchSettle, "settle", 2*time.Second, "How long a new file must be unchanged before it's processed")
+	watchCmd.Flags().BoolVar(&watchMoveOriginals, "move-originals", false, "Move originals into processed/ or failed/ subdirectories")
+	addProcessingFlags(watchCmd.Flags())
+	RootCmd.AddCommand(watchCmd)
+}
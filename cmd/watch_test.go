@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/processor"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var _ = Describe("moveOriginal", func() {
+	var (
+		dir  string
+		path string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "removebg-watch")
+		Expect(err).ToNot(HaveOccurred())
+
+		path = filepath.Join(dir, "a.jpg")
+		Expect(ioutil.WriteFile(path, []byte("image"), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("moves processed images into processed/", func() {
+		moved, err := moveOriginal(path, processor.Summary{Total: 1, Processed: 1})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(moved).To(Equal(filepath.Join(dir, "processed", "a.jpg")))
+	})
+
+	It("moves skipped images into processed/", func() {
+		moved, err := moveOriginal(path, processor.Summary{Total: 1, Skipped: 1})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(moved).To(Equal(filepath.Join(dir, "processed", "a.jpg")))
+	})
+
+	It("moves failed images into failed/", func() {
+		moved, err := moveOriginal(path, processor.Summary{Total: 1, Failed: 1})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(moved).To(Equal(filepath.Join(dir, "failed", "a.jpg")))
+	})
+
+	It("leaves images which weren't attempted", func() {
+		moved, err := moveOriginal(path, processor.Summary{Total: 1, Cancelled: true})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(moved).To(Equal(path))
+		Expect(path).To(BeAnExistingFile())
+	})
+})
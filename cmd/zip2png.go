@@ -15,7 +15,7 @@ var zip2pngCmd = &cobra.Command{
 		outputImagePath := args[1]
 		composite := composite.New()
 
-		err := composite.Process(inputZipPath, outputImagePath)
+		err := composite.Process(cmd.Context(), inputZipPath, outputImagePath)
 
 		if err != nil {
 			return err
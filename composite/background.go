@@ -0,0 +1,253 @@
+package composite
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// BackgroundMode is how a background image is sized to the foreground
+type BackgroundMode string
+
+const (
+	BackgroundFit    BackgroundMode = "fit"    // Scaled to fit inside, leaving the rest transparent
+	BackgroundFill   BackgroundMode = "fill"   // Scaled to cover, cropping the overflow
+	BackgroundCenter BackgroundMode = "center" // Centered at its original size
+	BackgroundTile   BackgroundMode = "tile"   // Repeated from the top left at its original size
+)
+
+var BackgroundModes = []BackgroundMode{BackgroundFit, BackgroundFill, BackgroundCenter, BackgroundTile}
+
+// Background is drawn behind the foreground. The color is drawn first, so
+// it shows through any transparent areas of the image.
+type Background struct {
+	Color *color.NRGBA
+	Image image.Image
+	Mode  BackgroundMode
+}
+
+func (b Background) IsSet() bool {
+	return b.Color != nil || b.Image != nil
+}
+
+func ParseBackgroundMode(mode string) (BackgroundMode, error) {
+	for _, m := range BackgroundModes {
+		if BackgroundMode(strings.ToLower(mode)) == m {
+			return m, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unknown background mode: %s (expected fit, fill, center or tile)", mode)
+}
+
+var namedColors = map[string]color.NRGBA{
+	"black":   {0x00, 0x00, 0x00, 0xff},
+	"white":   {0xff, 0xff, 0xff, 0xff},
+	"gray":    {0x80, 0x80, 0x80, 0xff},
+	"grey":    {0x80, 0x80, 0x80, 0xff},
+	"silver":  {0xc0, 0xc0, 0xc0, 0xff},
+	"red":     {0xff, 0x00, 0x00, 0xff},
+	"maroon":  {0x80, 0x00, 0x00, 0xff},
+	"orange":  {0xff, 0xa5, 0x00, 0xff},
+	"yellow":  {0xff, 0xff, 0x00, 0xff},
+	"olive":   {0x80, 0x80, 0x00, 0xff},
+	"lime":    {0x00, 0xff, 0x00, 0xff},
+	"green":   {0x00, 0x80, 0x00, 0xff},
+	"aqua":    {0x00, 0xff, 0xff, 0xff},
+	"cyan":    {0x00, 0xff, 0xff, 0xff},
+	"teal":    {0x00, 0x80, 0x80, 0xff},
+	"blue":    {0x00, 0x00, 0xff, 0xff},
+	"navy":    {0x00, 0x00, 0x80, 0xff},
+	"fuchsia": {0xff, 0x00, 0xff, 0xff},
+	"magenta": {0xff, 0x00, 0xff, 0xff},
+	"purple":  {0x80, 0x00, 0x80, 0xff},
+}
+
+// ParseColor accepts the same colors as the API's bg_color: hex codes with an
+// optional alpha (e.g. 81d4fa, fff or 81d4fa77) and basic color names
+func ParseColor(value string) (color.NRGBA, error) {
+	if named, ok := namedColors[strings.ToLower(value)]; ok {
+		return named, nil
+	}
+
+	hex := strings.TrimPrefix(value, "#")
+
+	// Expand the shorthand, e.g. fff -> ffffff
+	if len(hex) == 3 || len(hex) == 4 {
+		expanded := ""
+		for _, digit := range hex {
+			expanded += strings.Repeat(string(digit), 2)
+		}
+
+		hex = expanded
+	}
+
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	parsed, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 8 || err != nil {
+		return color.NRGBA{}, fmt.Errorf("Unable to parse color: %s", value)
+	}
+
+	return color.NRGBA{
+		R: uint8(parsed >> 24),
+		G: uint8(parsed >> 16),
+		B: uint8(parsed >> 8),
+		A: uint8(parsed),
+	}, nil
+}
+
+// DecodeImageFile decodes a JPEG or PNG image
+func DecodeImageFile(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read background image: %s", path)
+	}
+
+	defer file.Close()
+
+	decoded, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to decode background image %s: %s", path, err)
+	}
+
+	return decoded, nil
+}
+
+// applyBackground draws the foreground over the background
+func applyBackground(foreground image.Image, background Background) *image.RGBA {
+	bounds := foreground.Bounds()
+	canvas := image.NewRGBA(bounds)
+
+	if background.Color != nil {
+		draw.Draw(canvas, bounds, image.NewUniform(*background.Color), image.Point{}, draw.Src)
+	}
+
+	if background.Image != nil {
+		drawBackgroundImage(canvas, background.Image, background.Mode)
+	}
+
+	draw.Draw(canvas, bounds, foreground, bounds.Min, draw.Over)
+
+	return canvas
+}
+
+func drawBackgroundImage(canvas *image.RGBA, img image.Image, mode BackgroundMode) {
+	bounds := canvas.Bounds()
+	size := img.Bounds().Size()
+
+	switch mode {
+	case BackgroundTile:
+		for y := bounds.Min.Y; y < bounds.Max.Y; y += size.Y {
+			for x := bounds.Min.X; x < bounds.Max.X; x += size.X {
+				draw.Draw(canvas, image.Rect(x, y, x+size.X, y+size.Y), img, img.Bounds().Min, draw.Over)
+			}
+		}
+
+		return
+	case BackgroundCenter:
+		// Drawn at its original size
+	case BackgroundFit:
+		img = scale(img, scaledSize(size, bounds.Size(), false))
+	default:
+		img = scale(img, scaledSize(size, bounds.Size(), true))
+	}
+
+	size = img.Bounds().Size()
+	offset := bounds.Min.Add(bounds.Size().Sub(size).Div(2))
+
+	draw.Draw(canvas, image.Rectangle{Min: offset, Max: offset.Add(size)}, img, img.Bounds().Min, draw.Over)
+}
+
+// scaledSize keeps the aspect ratio, either covering the target or fitting
+// inside it
+func scaledSize(size image.Point, target image.Point, cover bool) image.Point {
+	scaleX := float64(target.X) / float64(size.X)
+	scaleY := float64(target.Y) / float64(size.Y)
+
+	factor := scaleX
+	if (cover && scaleY > scaleX) || (!cover && scaleY < scaleX) {
+		factor = scaleY
+	}
+
+	scaled := image.Pt(int(float64(size.X)*factor+0.5), int(float64(size.Y)*factor+0.5))
+
+	if scaled.X < 1 {
+		scaled.X = 1
+	}
+
+	if scaled.Y < 1 {
+		scaled.Y = 1
+	}
+
+	return scaled
+}
+
+// scale resizes the image with bilinear interpolation
+func scale(src image.Image, size image.Point) *image.NRGBA {
+	srcBounds := src.Bounds()
+	source := image.NewNRGBA(image.Rect(0, 0, srcBounds.Dx(), srcBounds.Dy()))
+	draw.Draw(source, source.Rect, src, srcBounds.Min, draw.Src)
+
+	scaled := image.NewNRGBA(image.Rect(0, 0, size.X, size.Y))
+	ratioX := float64(source.Rect.Dx()) / float64(size.X)
+	ratioY := float64(source.Rect.Dy()) / float64(size.Y)
+	maxX := source.Rect.Dx() - 1
+	maxY := source.Rect.Dy() - 1
+
+	inRowBands(scaled.Rect, func(band image.Rectangle) {
+		for y := band.Min.Y; y < band.Max.Y; y++ {
+			// Sample from the center of the pixel
+			srcY := clampFloat((float64(y)+0.5)*ratioY-0.5, 0, float64(maxY))
+			y0 := int(srcY)
+			y1 := minInt(y0+1, maxY)
+			weightY := srcY - float64(y0)
+
+			for x := 0; x < size.X; x++ {
+				srcX := clampFloat((float64(x)+0.5)*ratioX-0.5, 0, float64(maxX))
+				x0 := int(srcX)
+				x1 := minInt(x0+1, maxX)
+				weightX := srcX - float64(x0)
+
+				out := scaled.Pix[scaled.PixOffset(x, y):]
+				for c := 0; c < 4; c++ {
+					top := lerp(source.Pix[source.PixOffset(x0, y0)+c], source.Pix[source.PixOffset(x1, y0)+c], weightX)
+					bottom := lerp(source.Pix[source.PixOffset(x0, y1)+c], source.Pix[source.PixOffset(x1, y1)+c], weightX)
+					out[c] = uint8(top + (bottom-top)*weightY + 0.5)
+				}
+			}
+		}
+	})
+
+	return scaled
+}
+
+func lerp(a uint8, b uint8, weight float64) float64 {
+	return float64(a) + (float64(b)-float64(a))*weight
+}
+
+func clampFloat(value float64, min float64, max float64) float64 {
+	if value < min {
+		return min
+	}
+
+	if value > max {
+		return max
+	}
+
+	return value
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
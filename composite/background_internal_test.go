@@ -0,0 +1,147 @@
+package composite
+
+import (
+	"image"
+	"image/color"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	red         = color.NRGBA{R: 0xff, A: 0xff}
+	blue        = color.NRGBA{B: 0xff, A: 0xff}
+	green       = color.NRGBA{G: 0xff, A: 0xff}
+	transparent = color.NRGBA{}
+)
+
+// A 2x1 image, red on the left and blue on the right
+func redBlue() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, red)
+	img.SetNRGBA(1, 0, blue)
+	return img
+}
+
+// A 1x2 image, red on top and blue below
+func redOverBlue() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 2))
+	img.SetNRGBA(0, 0, red)
+	img.SetNRGBA(0, 1, blue)
+	return img
+}
+
+func rows(img image.Image) [][]color.NRGBA {
+	bounds := img.Bounds()
+	pixels := [][]color.NRGBA{}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row := []color.NRGBA{}
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			row = append(row, color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA))
+		}
+
+		pixels = append(pixels, row)
+	}
+
+	return pixels
+}
+
+var _ = Describe("applyBackground", func() {
+	var foreground *image.NRGBA
+
+	BeforeEach(func() {
+		// Transparent apart from an opaque green top left pixel
+		foreground = image.NewNRGBA(image.Rect(0, 0, 4, 2))
+		foreground.SetNRGBA(0, 0, green)
+	})
+
+	It("draws the foreground over a color", func() {
+		composited := applyBackground(foreground, Background{Color: &blue})
+
+		Expect(rows(composited)).To(Equal([][]color.NRGBA{
+			{green, blue, blue, blue},
+			{blue, blue, blue, blue},
+		}))
+	})
+
+	It("tiles the image", func() {
+		composited := applyBackground(foreground, Background{Image: redBlue(), Mode: BackgroundTile})
+
+		Expect(rows(composited)).To(Equal([][]color.NRGBA{
+			{green, blue, red, blue},
+			{red, blue, red, blue},
+		}))
+	})
+
+	It("centers the image at its original size, over the color", func() {
+		composited := applyBackground(foreground, Background{Color: &blue, Image: redBlue(), Mode: BackgroundCenter})
+
+		Expect(rows(composited)).To(Equal([][]color.NRGBA{
+			{green, red, blue, blue},
+			{blue, blue, blue, blue},
+		}))
+	})
+
+	It("scales the image to fit inside, leaving the rest transparent", func() {
+		composited := applyBackground(foreground, Background{Image: redOverBlue(), Mode: BackgroundFit})
+
+		Expect(rows(composited)).To(Equal([][]color.NRGBA{
+			{green, red, transparent, transparent},
+			{transparent, blue, transparent, transparent},
+		}))
+	})
+
+	It("scales the image to cover, cropping the overflow", func() {
+		composited := applyBackground(foreground, Background{Image: redOverBlue(), Mode: BackgroundFill})
+
+		// Scaled to 4x8, so only the middle rows remain
+		blended := color.NRGBA{R: 0x60, B: 0x9f, A: 0xff}
+		Expect(rows(composited)[1]).To(Equal([]color.NRGBA{blended, blended, blended, blended}))
+		Expect(composited.Bounds()).To(Equal(foreground.Bounds()))
+	})
+})
+
+var _ = Describe("scaledSize", func() {
+	It("fits inside the target", func() {
+		Expect(scaledSize(image.Pt(200, 100), image.Pt(100, 100), false)).To(Equal(image.Pt(100, 50)))
+	})
+
+	It("covers the target", func() {
+		Expect(scaledSize(image.Pt(200, 100), image.Pt(100, 100), true)).To(Equal(image.Pt(200, 100)))
+	})
+})
+
+var _ = Describe("ParseColor", func() {
+	It("parses hex colors", func() {
+		Expect(ParseColor("81d4fa")).To(Equal(color.NRGBA{R: 0x81, G: 0xd4, B: 0xfa, A: 0xff}))
+		Expect(ParseColor("#81d4fa77")).To(Equal(color.NRGBA{R: 0x81, G: 0xd4, B: 0xfa, A: 0x77}))
+	})
+
+	It("parses shorthand hex colors", func() {
+		Expect(ParseColor("fff")).To(Equal(color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}))
+		Expect(ParseColor("f008")).To(Equal(color.NRGBA{R: 0xff, A: 0x88}))
+	})
+
+	It("parses color names", func() {
+		Expect(ParseColor("Green")).To(Equal(color.NRGBA{G: 0x80, A: 0xff}))
+	})
+
+	It("rejects anything else", func() {
+		_, err := ParseColor("81d4fz")
+
+		Expect(err).To(MatchError("Unable to parse color: 81d4fz"))
+	})
+})
+
+var _ = Describe("ParseBackgroundMode", func() {
+	It("parses the modes", func() {
+		Expect(ParseBackgroundMode("Tile")).To(Equal(BackgroundTile))
+	})
+
+	It("rejects unknown modes", func() {
+		_, err := ParseBackgroundMode("stretch")
+
+		Expect(err).To(MatchError("Unknown background mode: stretch (expected fit, fill, center or tile)"))
+	})
+})
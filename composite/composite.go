@@ -5,19 +5,37 @@ import (
 
 	"archive/zip"
 	"bytes"
+	"context"
 	"fmt"
 	"image"
 	"image/color"
+	"image/draw"
 	"image/jpeg"
 	"image/png"
 	"io"
+	"io/ioutil"
+	"runtime"
+	"sync"
 )
 
 //go:generate counterfeiter . CompositorInterface
 type CompositorInterface interface {
-	Process(inputZipPath string, outputImagePath string) error
+	Process(ctx context.Context, inputZipPath string, outputImagePath string) error
+	Composite(ctx context.Context, zipData io.ReaderAt, size int64, output io.Writer, options Options) error
+	Render(ctx context.Context, colorImage io.Reader, mask io.Reader, output io.Writer, options Options) error
 }
 
+// Options for the composited image
+type Options struct {
+	Background Background // Optional
+	Format     string     // png (the default) or jpg
+}
+
+const (
+	FormatPng = "png"
+	FormatJpg = "jpg"
+)
+
 type Compositor struct {
 	Storage storage.StorageInterface
 }
@@ -30,7 +48,9 @@ func New() Compositor {
 	}
 }
 
-func (c Compositor) Process(inputZipPath string, outputImagePath string) error {
+// Process is only cancellable up to the point the output starts being written,
+// so a cancelled context never leaves a partial image behind
+func (c Compositor) Process(ctx context.Context, inputZipPath string, outputImagePath string) error {
 	if !c.Storage.FileExists(inputZipPath) {
 		return fmt.Errorf("Could not locate zip: %s", inputZipPath)
 	}
@@ -41,19 +61,105 @@ func (c Compositor) Process(inputZipPath string, outputImagePath string) error {
 		return err
 	}
 
-	composited := composite(rgb, alpha)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	c.savePng(composited, outputImagePath)
+	composited := composite(rgb, alpha)
 
-	return nil
+	return c.savePng(composited, outputImagePath)
 }
 
-func (c Compositor) savePng(image *image.NRGBA, outputPath string) {
+func (c Compositor) savePng(image *image.NRGBA, outputPath string) error {
 	buf := new(bytes.Buffer)
-	png.Encode(buf, image)
-	c.Storage.Write(outputPath, buf.Bytes())
+
+	err := png.Encode(buf, image)
+	if err != nil {
+		return err
+	}
+
+	return c.Storage.Write(outputPath, buf.Bytes())
+}
+
+// Composite combines the images in a remove.bg ZIP without touching the disk,
+// writing the image to output. Like Process, it's only cancellable up to the
+// point the output starts being written.
+func (c Compositor) Composite(ctx context.Context, zipData io.ReaderAt, size int64, output io.Writer, options Options) error {
+	archive, err := zip.NewReader(zipData, size)
+	if err != nil {
+		return err
+	}
+
+	rgb, alpha, err := extractImages(archive)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return encode(composite(rgb, alpha), output, options)
+}
+
+// Render composites a mask from a ZIP (see ReadMask) with the original color
+// image, so the result can be regenerated without calling the API. The color
+// image is scaled to the mask's size, as the API may have resized it.
+func (c Compositor) Render(ctx context.Context, colorImage io.Reader, mask io.Reader, output io.Writer, options Options) error {
+	alpha, err := png.Decode(mask)
+	if err != nil {
+		return fmt.Errorf("Unable to decode mask: %s", err)
+	}
+
+	rgb, _, err := image.Decode(colorImage)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	size := alpha.Bounds().Size()
+	if rgb.Bounds().Size() != size {
+		rgb = scale(rgb, size)
+	}
+
+	return encode(composite(rgb, grayscale(alpha)), output, options)
+}
+
+func encode(composited *image.NRGBA, output io.Writer, options Options) error {
+	var img image.Image = composited
+	if options.Background.IsSet() {
+		img = applyBackground(composited, options.Background)
+	}
+
+	if options.Format == FormatJpg {
+		return jpeg.Encode(output, img, &jpeg.Options{Quality: jpegQuality})
+	}
+
+	return png.Encode(output, img)
+}
+
+// ReadMask returns the alpha mask from a remove.bg ZIP, still PNG encoded
+func ReadMask(zipData io.ReaderAt, size int64) ([]byte, error) {
+	archive, err := zip.NewReader(zipData, size)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := openZipFile(archive, zipAlphaImageFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rc.Close()
+
+	return ioutil.ReadAll(rc)
 }
 
+const jpegQuality = 90
+
 const zipColorImageFileName = "color.jpg"
 const zipAlphaImageFileName = "alpha.png"
 
@@ -65,6 +171,10 @@ func extractImagesFromZip(filename string) (rgb image.Image, alpha image.Image,
 
 	defer archive.Close()
 
+	return extractImages(&archive.Reader)
+}
+
+func extractImages(archive *zip.Reader) (rgb image.Image, alpha image.Image, err error) {
 	alpha, err = decodeZipImage(archive, zipAlphaImageFileName, png.Decode)
 	if err != nil {
 		return nil, nil, err
@@ -78,33 +188,65 @@ func extractImagesFromZip(filename string) (rgb image.Image, alpha image.Image,
 	return rgb, alpha, nil
 }
 
-func decodeZipImage(archive *zip.ReadCloser, fileName string, decoder imageDecoder) (image.Image, error) {
-	for _, f := range archive.File {
-		if f.Name == fileName {
-			rc, err := f.Open()
-			defer rc.Close()
+func decodeZipImage(archive *zip.Reader, fileName string, decoder imageDecoder) (image.Image, error) {
+	rc, err := openZipFile(archive, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rc.Close()
 
-			if err != nil {
-				return nil, err
-			}
+	return decoder(rc)
+}
 
-			return decoder(rc)
+func openZipFile(archive *zip.Reader, fileName string) (io.ReadCloser, error) {
+	for _, f := range archive.File {
+		if f.Name == fileName {
+			return f.Open()
 		}
 	}
 
 	return nil, fmt.Errorf("Unable to find image in ZIP: %s", fileName)
 }
 
+// grayscale converts a mask which wasn't saved as a grayscale PNG
+func grayscale(mask image.Image) *image.Gray {
+	if gray, ok := mask.(*image.Gray); ok {
+		return gray
+	}
+
+	bounds := mask.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(gray, gray.Rect, mask, bounds.Min, draw.Src)
+
+	return gray
+}
+
 func composite(rgb image.Image, alpha image.Image) *image.NRGBA {
 	dimensions := rgb.Bounds().Max
-	width := dimensions.X
-	height := dimensions.Y
+	composited := image.NewNRGBA(image.Rect(0, 0, dimensions.X, dimensions.Y))
+
+	// The API returns a JPEG and a grayscale PNG, which decode to these types
+	ycbcr, isYCbCr := rgb.(*image.YCbCr)
+	gray, isGray := alpha.(*image.Gray)
 
-	composited := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if isYCbCr && isGray && composited.Rect.In(ycbcr.Rect) && composited.Rect.In(gray.Rect) {
+		inRowBands(composited.Rect, func(band image.Rectangle) {
+			compositeYCbCrGray(ycbcr, gray, composited, band)
+		})
+	} else {
+		compositeAny(rgb, alpha, composited, composited.Rect)
+	}
+
+	return composited
+}
+
+// compositeAny works with any image types, but is slow
+func compositeAny(rgb image.Image, alpha image.Image, composited *image.NRGBA, band image.Rectangle) {
 	colorModel := composited.ColorModel()
 
-	for x := 0; x < width; x++ {
-		for y := 0; y < height; y++ {
+	for y := band.Min.Y; y < band.Max.Y; y++ {
+		for x := band.Min.X; x < band.Max.X; x++ {
 			rgbColor := (colorModel.Convert(rgb.At(x, y))).(color.NRGBA)
 			alphaColor := (alpha.At(x, y)).(color.Gray)
 			rgbColor.A = alphaColor.Y
@@ -112,6 +254,62 @@ func composite(rgb image.Image, alpha image.Image) *image.NRGBA {
 			composited.SetNRGBA(x, y, rgbColor)
 		}
 	}
+}
 
-	return composited
+// compositeYCbCrGray reads the pixels directly, giving the same result as
+// compositeAny
+func compositeYCbCrGray(rgb *image.YCbCr, alpha *image.Gray, composited *image.NRGBA, band image.Rectangle) {
+	for y := band.Min.Y; y < band.Max.Y; y++ {
+		alphaRow := alpha.Pix[alpha.PixOffset(band.Min.X, y):]
+		out := composited.Pix[composited.PixOffset(band.Min.X, y):]
+
+		for x := band.Min.X; x < band.Max.X; x++ {
+			yi := rgb.YOffset(x, y)
+			ci := rgb.COffset(x, y)
+
+			// Converting via 16 bits matches the NRGBA color model exactly
+			r, g, b, _ := color.YCbCr{Y: rgb.Y[yi], Cb: rgb.Cb[ci], Cr: rgb.Cr[ci]}.RGBA()
+
+			i := (x - band.Min.X) * 4
+			out[i+0] = uint8(r >> 8)
+			out[i+1] = uint8(g >> 8)
+			out[i+2] = uint8(b >> 8)
+			out[i+3] = alphaRow[x-band.Min.X]
+		}
+	}
+}
+
+// Images smaller than this aren't worth splitting between goroutines
+const minParallelPixels = 256 * 256
+
+// inRowBands splits the rectangle into a band of rows per CPU, and calls fn
+// for each band in parallel
+func inRowBands(rect image.Rectangle, fn func(band image.Rectangle)) {
+	bands := runtime.GOMAXPROCS(0)
+	if bands > rect.Dy() {
+		bands = rect.Dy()
+	}
+
+	if bands <= 1 || rect.Dx()*rect.Dy() < minParallelPixels {
+		fn(rect)
+		return
+	}
+
+	rowsPerBand := (rect.Dy() + bands - 1) / bands
+
+	var wg sync.WaitGroup
+	for minY := rect.Min.Y; minY < rect.Max.Y; minY += rowsPerBand {
+		maxY := minY + rowsPerBand
+		if maxY > rect.Max.Y {
+			maxY = rect.Max.Y
+		}
+
+		wg.Add(1)
+		go func(band image.Rectangle) {
+			defer wg.Done()
+			fn(band)
+		}(image.Rect(rect.Min.X, minY, rect.Max.X, maxY))
+	}
+
+	wg.Wait()
 }
@@ -0,0 +1,111 @@
+package composite
+
+import (
+	"archive/zip"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const exampleZip = "../fixtures/zip/example-cat.zip"
+
+func loadExample() (image.Image, image.Image, error) {
+	archive, err := zip.OpenReader(exampleZip)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer archive.Close()
+
+	return extractImages(&archive.Reader)
+}
+
+func compositeSlow(rgb image.Image, alpha image.Image) *image.NRGBA {
+	dimensions := rgb.Bounds().Max
+	composited := image.NewNRGBA(image.Rect(0, 0, dimensions.X, dimensions.Y))
+	compositeAny(rgb, alpha, composited, composited.Rect)
+	return composited
+}
+
+func randomYCbCrGray(width int, height int, ratio image.YCbCrSubsampleRatio) (*image.YCbCr, *image.Gray) {
+	random := rand.New(rand.NewSource(1))
+	rect := image.Rect(0, 0, width, height)
+
+	rgb := image.NewYCbCr(rect, ratio)
+	random.Read(rgb.Y)
+	random.Read(rgb.Cb)
+	random.Read(rgb.Cr)
+
+	alpha := image.NewGray(rect)
+	random.Read(alpha.Pix)
+
+	return rgb, alpha
+}
+
+var _ = Describe("composite", func() {
+	It("gives the same result on the fast path for the example ZIP", func() {
+		rgb, alpha, err := loadExample()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rgb).To(BeAssignableToTypeOf(&image.YCbCr{}))
+		Expect(alpha).To(BeAssignableToTypeOf(&image.Gray{}))
+
+		Expect(composite(rgb, alpha).Pix).To(Equal(compositeSlow(rgb, alpha).Pix))
+	})
+
+	It("gives the same result on the fast path for every subsample ratio", func() {
+		ratios := []image.YCbCrSubsampleRatio{
+			image.YCbCrSubsampleRatio444,
+			image.YCbCrSubsampleRatio422,
+			image.YCbCrSubsampleRatio420,
+			image.YCbCrSubsampleRatio440,
+			image.YCbCrSubsampleRatio411,
+			image.YCbCrSubsampleRatio410,
+		}
+
+		for _, ratio := range ratios {
+			// Odd dimensions, big enough to be split into bands
+			rgb, alpha := randomYCbCrGray(301, 457, ratio)
+
+			Expect(composite(rgb, alpha).Pix).To(Equal(compositeSlow(rgb, alpha).Pix), ratio.String())
+		}
+	})
+
+	It("falls back for other image types", func() {
+		rgb := image.NewRGBA(image.Rect(0, 0, 2, 1))
+		rgb.Set(0, 0, color.RGBA{R: 10, G: 20, B: 30, A: 255})
+		alpha := image.NewGray(image.Rect(0, 0, 2, 1))
+		alpha.SetGray(0, 0, color.Gray{Y: 128})
+
+		composited := composite(rgb, alpha)
+
+		Expect(composited.NRGBAAt(0, 0)).To(Equal(color.NRGBA{R: 10, G: 20, B: 30, A: 128}))
+	})
+})
+
+func BenchmarkComposite(b *testing.B) {
+	rgb, alpha, err := loadExample()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		composite(rgb, alpha)
+	}
+}
+
+func BenchmarkCompositeSlow(b *testing.B) {
+	rgb, alpha, err := loadExample()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		compositeSlow(rgb, alpha)
+	}
+}
@@ -1,11 +1,20 @@
 package composite_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	. "github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/config"
 	. "github.com/onsi/gomega"
 	"github.com/remove-bg/go/composite"
+	"github.com/remove-bg/go/storage/storagefakes"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"runtime"
@@ -34,11 +43,21 @@ var _ = Describe("Composite", func() {
 
 	Context("when the input zip does not exist", func() {
 		It("returns an error", func() {
-			Expect(subject.Process("missing.zip", outputPath)).To(MatchError("Could not locate zip: missing.zip"))
+			Expect(subject.Process(context.Background(), "missing.zip", outputPath)).To(MatchError("Could not locate zip: missing.zip"))
 		})
 
 		It("does not write any output", func() {
-			Expect(subject.Process("missing.zip", outputPath)).To(HaveOccurred())
+			Expect(subject.Process(context.Background(), "missing.zip", outputPath)).To(HaveOccurred())
+			Expect(outputPath).ToNot(BeAnExistingFile())
+		})
+	})
+
+	Context("when the context is cancelled", func() {
+		It("does not write any output", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			Expect(subject.Process(ctx, exampleZip, outputPath)).To(MatchError(context.Canceled))
 			Expect(outputPath).ToNot(BeAnExistingFile())
 		})
 	})
@@ -48,7 +67,7 @@ var _ = Describe("Composite", func() {
 			exampleZip = path.Join(testDir, "../fixtures/zip/example-missing-color.zip")
 			Expect(exampleZip).To(BeAnExistingFile())
 
-			Expect(subject.Process(exampleZip, outputPath)).To(MatchError("Unable to find image in ZIP: color.jpg"))
+			Expect(subject.Process(context.Background(), exampleZip, outputPath)).To(MatchError("Unable to find image in ZIP: color.jpg"))
 		})
 	})
 
@@ -57,7 +76,111 @@ var _ = Describe("Composite", func() {
 			exampleZip = path.Join(testDir, "../fixtures/zip/example-missing-alpha.zip")
 			Expect(exampleZip).To(BeAnExistingFile())
 
-			Expect(subject.Process(exampleZip, outputPath)).To(MatchError("Unable to find image in ZIP: alpha.png"))
+			Expect(subject.Process(context.Background(), exampleZip, outputPath)).To(MatchError("Unable to find image in ZIP: alpha.png"))
+		})
+	})
+
+	Context("when the output can't be written", func() {
+		It("returns the error", func() {
+			fakeStorage := &storagefakes.FakeStorageInterface{}
+			fakeStorage.FileExistsReturns(true)
+			fakeStorage.WriteReturns(errors.New("disk full"))
+			subject.Storage = fakeStorage
+
+			Expect(subject.Process(context.Background(), exampleZip, outputPath)).To(MatchError("disk full"))
+		})
+	})
+
+	Describe("Composite", func() {
+		It("writes the same PNG as processing the ZIP file", func() {
+			dir, err := ioutil.TempDir("", "removebg-composite")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			outputPath = path.Join(dir, "composite-cat.png")
+			Expect(subject.Process(context.Background(), exampleZip, outputPath)).To(Succeed())
+			expected, err := ioutil.ReadFile(outputPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			zipData, err := ioutil.ReadFile(exampleZip)
+			Expect(err).ToNot(HaveOccurred())
+			out := &bytes.Buffer{}
+
+			Expect(subject.Composite(context.Background(), bytes.NewReader(zipData), int64(len(zipData)), out, composite.Options{})).To(Succeed())
+			Expect(out.Bytes()).To(Equal(expected))
+		})
+
+		It("returns an error for invalid ZIP data", func() {
+			zipData := []byte("not a zip")
+
+			Expect(subject.Composite(context.Background(), bytes.NewReader(zipData), int64(len(zipData)), &bytes.Buffer{}, composite.Options{})).To(HaveOccurred())
+		})
+
+		It("doesn't write any output when the context is cancelled", func() {
+			zipData, err := ioutil.ReadFile(exampleZip)
+			Expect(err).ToNot(HaveOccurred())
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			out := &bytes.Buffer{}
+
+			Expect(subject.Composite(ctx, bytes.NewReader(zipData), int64(len(zipData)), out, composite.Options{})).To(MatchError(context.Canceled))
+			Expect(out.Len()).To(BeZero())
+		})
+	})
+
+	Describe("Render", func() {
+		var (
+			zipData []byte
+			mask    []byte
+		)
+
+		BeforeEach(func() {
+			var err error
+			zipData, err = ioutil.ReadFile(exampleZip)
+			Expect(err).ToNot(HaveOccurred())
+
+			mask, err = composite.ReadMask(bytes.NewReader(zipData), int64(len(zipData)))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("writes the same image as compositing the ZIP", func() {
+			expected := &bytes.Buffer{}
+			Expect(subject.Composite(context.Background(), bytes.NewReader(zipData), int64(len(zipData)), expected, composite.Options{})).To(Succeed())
+
+			archive, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+			Expect(err).ToNot(HaveOccurred())
+			var colorImage io.Reader
+			for _, f := range archive.File {
+				if f.Name == "color.jpg" {
+					colorImage, err = f.Open()
+					Expect(err).ToNot(HaveOccurred())
+				}
+			}
+
+			out := &bytes.Buffer{}
+			Expect(subject.Render(context.Background(), colorImage, bytes.NewReader(mask), out, composite.Options{})).To(Succeed())
+			Expect(out.Bytes()).To(Equal(expected.Bytes()))
+		})
+
+		It("scales the color image to the mask's size", func() {
+			colorImage := &bytes.Buffer{}
+			Expect(png.Encode(colorImage, image.NewNRGBA(image.Rect(0, 0, 10, 10)))).To(Succeed())
+
+			out := &bytes.Buffer{}
+			Expect(subject.Render(context.Background(), colorImage, bytes.NewReader(mask), out, composite.Options{})).To(Succeed())
+
+			rendered, err := png.DecodeConfig(out)
+			Expect(err).ToNot(HaveOccurred())
+			maskConfig, err := png.DecodeConfig(bytes.NewReader(mask))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rendered.Width).To(Equal(maskConfig.Width))
+			Expect(rendered.Height).To(Equal(maskConfig.Height))
+		})
+
+		It("returns an error for an invalid mask", func() {
+			err := subject.Render(context.Background(), &bytes.Buffer{}, bytes.NewReader([]byte("not a mask")), &bytes.Buffer{}, composite.Options{})
+
+			Expect(err).To(MatchError(HavePrefix("Unable to decode mask")))
 		})
 	})
 })
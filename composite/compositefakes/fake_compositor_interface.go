@@ -2,17 +2,35 @@
 package compositefakes
 
 import (
+	"context"
+	"io"
 	"sync"
 
 	"github.com/remove-bg/go/composite"
 )
 
 type FakeCompositorInterface struct {
-	ProcessStub        func(string, string) error
+	CompositeStub        func(context.Context, io.ReaderAt, int64, io.Writer, composite.Options) error
+	compositeMutex       sync.RWMutex
+	compositeArgsForCall []struct {
+		arg1 context.Context
+		arg2 io.ReaderAt
+		arg3 int64
+		arg4 io.Writer
+		arg5 composite.Options
+	}
+	compositeReturns struct {
+		result1 error
+	}
+	compositeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ProcessStub        func(context.Context, string, string) error
 	processMutex       sync.RWMutex
 	processArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
+		arg3 string
 	}
 	processReturns struct {
 		result1 error
@@ -20,26 +38,108 @@ type FakeCompositorInterface struct {
 	processReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RenderStub        func(context.Context, io.Reader, io.Reader, io.Writer, composite.Options) error
+	renderMutex       sync.RWMutex
+	renderArgsForCall []struct {
+		arg1 context.Context
+		arg2 io.Reader
+		arg3 io.Reader
+		arg4 io.Writer
+		arg5 composite.Options
+	}
+	renderReturns struct {
+		result1 error
+	}
+	renderReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeCompositorInterface) Process(arg1 string, arg2 string) error {
+func (fake *FakeCompositorInterface) Composite(arg1 context.Context, arg2 io.ReaderAt, arg3 int64, arg4 io.Writer, arg5 composite.Options) error {
+	fake.compositeMutex.Lock()
+	ret, specificReturn := fake.compositeReturnsOnCall[len(fake.compositeArgsForCall)]
+	fake.compositeArgsForCall = append(fake.compositeArgsForCall, struct {
+		arg1 context.Context
+		arg2 io.ReaderAt
+		arg3 int64
+		arg4 io.Writer
+		arg5 composite.Options
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.CompositeStub
+	fakeReturns := fake.compositeReturns
+	fake.recordInvocation("Composite", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.compositeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCompositorInterface) CompositeCallCount() int {
+	fake.compositeMutex.RLock()
+	defer fake.compositeMutex.RUnlock()
+	return len(fake.compositeArgsForCall)
+}
+
+func (fake *FakeCompositorInterface) CompositeCalls(stub func(context.Context, io.ReaderAt, int64, io.Writer, composite.Options) error) {
+	fake.compositeMutex.Lock()
+	defer fake.compositeMutex.Unlock()
+	fake.CompositeStub = stub
+}
+
+func (fake *FakeCompositorInterface) CompositeArgsForCall(i int) (context.Context, io.ReaderAt, int64, io.Writer, composite.Options) {
+	fake.compositeMutex.RLock()
+	defer fake.compositeMutex.RUnlock()
+	argsForCall := fake.compositeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeCompositorInterface) CompositeReturns(result1 error) {
+	fake.compositeMutex.Lock()
+	defer fake.compositeMutex.Unlock()
+	fake.CompositeStub = nil
+	fake.compositeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCompositorInterface) CompositeReturnsOnCall(i int, result1 error) {
+	fake.compositeMutex.Lock()
+	defer fake.compositeMutex.Unlock()
+	fake.CompositeStub = nil
+	if fake.compositeReturnsOnCall == nil {
+		fake.compositeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.compositeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCompositorInterface) Process(arg1 context.Context, arg2 string, arg3 string) error {
 	fake.processMutex.Lock()
 	ret, specificReturn := fake.processReturnsOnCall[len(fake.processArgsForCall)]
 	fake.processArgsForCall = append(fake.processArgsForCall, struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-	}{arg1, arg2})
-	fake.recordInvocation("Process", []interface{}{arg1, arg2})
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ProcessStub
+	fakeReturns := fake.processReturns
+	fake.recordInvocation("Process", []interface{}{arg1, arg2, arg3})
 	fake.processMutex.Unlock()
-	if fake.ProcessStub != nil {
-		return fake.ProcessStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.processReturns
 	return fakeReturns.result1
 }
 
@@ -49,17 +149,17 @@ func (fake *FakeCompositorInterface) ProcessCallCount() int {
 	return len(fake.processArgsForCall)
 }
 
-func (fake *FakeCompositorInterface) ProcessCalls(stub func(string, string) error) {
+func (fake *FakeCompositorInterface) ProcessCalls(stub func(context.Context, string, string) error) {
 	fake.processMutex.Lock()
 	defer fake.processMutex.Unlock()
 	fake.ProcessStub = stub
 }
 
-func (fake *FakeCompositorInterface) ProcessArgsForCall(i int) (string, string) {
+func (fake *FakeCompositorInterface) ProcessArgsForCall(i int) (context.Context, string, string) {
 	fake.processMutex.RLock()
 	defer fake.processMutex.RUnlock()
 	argsForCall := fake.processArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeCompositorInterface) ProcessReturns(result1 error) {
@@ -85,11 +185,80 @@ func (fake *FakeCompositorInterface) ProcessReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *FakeCompositorInterface) Render(arg1 context.Context, arg2 io.Reader, arg3 io.Reader, arg4 io.Writer, arg5 composite.Options) error {
+	fake.renderMutex.Lock()
+	ret, specificReturn := fake.renderReturnsOnCall[len(fake.renderArgsForCall)]
+	fake.renderArgsForCall = append(fake.renderArgsForCall, struct {
+		arg1 context.Context
+		arg2 io.Reader
+		arg3 io.Reader
+		arg4 io.Writer
+		arg5 composite.Options
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.RenderStub
+	fakeReturns := fake.renderReturns
+	fake.recordInvocation("Render", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.renderMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCompositorInterface) RenderCallCount() int {
+	fake.renderMutex.RLock()
+	defer fake.renderMutex.RUnlock()
+	return len(fake.renderArgsForCall)
+}
+
+func (fake *FakeCompositorInterface) RenderCalls(stub func(context.Context, io.Reader, io.Reader, io.Writer, composite.Options) error) {
+	fake.renderMutex.Lock()
+	defer fake.renderMutex.Unlock()
+	fake.RenderStub = stub
+}
+
+func (fake *FakeCompositorInterface) RenderArgsForCall(i int) (context.Context, io.Reader, io.Reader, io.Writer, composite.Options) {
+	fake.renderMutex.RLock()
+	defer fake.renderMutex.RUnlock()
+	argsForCall := fake.renderArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeCompositorInterface) RenderReturns(result1 error) {
+	fake.renderMutex.Lock()
+	defer fake.renderMutex.Unlock()
+	fake.RenderStub = nil
+	fake.renderReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCompositorInterface) RenderReturnsOnCall(i int, result1 error) {
+	fake.renderMutex.Lock()
+	defer fake.renderMutex.Unlock()
+	fake.RenderStub = nil
+	if fake.renderReturnsOnCall == nil {
+		fake.renderReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.renderReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeCompositorInterface) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.compositeMutex.RLock()
+	defer fake.compositeMutex.RUnlock()
 	fake.processMutex.RLock()
 	defer fake.processMutex.RUnlock()
+	fake.renderMutex.RLock()
+	defer fake.renderMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
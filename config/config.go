@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectFileName is looked for in the current directory
+const ProjectFileName = ".removebg.yaml"
+
+const APIKeyEnv = "REMOVE_BG_API_KEY"
+
+// File is a config file of flag names and their values, along with named
+// profiles of further values, e.g.
+//
+//	size: full
+//	profiles:
+//	  ecommerce:
+//	    type: product
+//	    bg-color: fff
+type File struct {
+	Values   map[string]interface{}            `yaml:",inline"`
+	Profiles map[string]map[string]interface{} `yaml:"profiles"`
+}
+
+// Source is where a group of values came from
+type Source struct {
+	Name   string
+	Values map[string]string
+}
+
+// Setting is the value of a flag, and where it came from
+type Setting struct {
+	Value  string
+	Source string
+}
+
+// Config resolves each flag from its sources, in increasing precedence
+type Config struct {
+	Sources []Source
+}
+
+// UserPath is ~/.config/removebg/config.yaml, or within $XDG_CONFIG_HOME
+func UserPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if len(dir) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+
+		dir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(dir, "removebg", "config.yaml")
+}
+
+// Load the user and project config files, either of which may not exist,
+// followed by the profile from either file and then the environment. An
+// empty profile isn't applied.
+func Load(userPath string, projectPath string, profile string) (Config, error) {
+	config := Config{}
+	profileFound := false
+	var profileSources []Source
+
+	for _, path := range []string{userPath, projectPath} {
+		file, err := ReadFile(path)
+		if err != nil {
+			return config, err
+		}
+
+		if file == nil {
+			continue
+		}
+
+		config.Sources = append(config.Sources, Source{Name: path, Values: stringValues(file.Values)})
+
+		if values, ok := file.Profiles[profile]; ok && len(profile) > 0 {
+			profileFound = true
+			profileSources = append(profileSources, Source{
+				Name:   fmt.Sprintf("profile %s (%s)", profile, path),
+				Values: stringValues(values),
+			})
+		}
+	}
+
+	if len(profile) > 0 && !profileFound {
+		return config, fmt.Errorf("Unknown profile: %s", profile)
+	}
+
+	config.Sources = append(config.Sources, profileSources...)
+
+	if apiKey := os.Getenv(APIKeyEnv); len(apiKey) > 0 {
+		config.Sources = append(config.Sources, Source{
+			Name:   APIKeyEnv + " environment variable",
+			Values: map[string]string{"api-key": apiKey},
+		})
+	}
+
+	return config, nil
+}
+
+// ReadFile returns nil if the file doesn't exist
+func ReadFile(path string) (*File, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	file := &File{}
+	err = yaml.UnmarshalStrict(data, file)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read %s: %s", path, err)
+	}
+
+	return file, nil
+}
+
+// Lookup the value from the highest precedence source which sets the flag
+func (c Config) Lookup(name string) (Setting, bool) {
+	for i := len(c.Sources) - 1; i >= 0; i-- {
+		if value, ok := c.Sources[i].Values[name]; ok {
+			return Setting{Value: value, Source: c.Sources[i].Name}, true
+		}
+	}
+
+	return Setting{}, false
+}
+
+// Names of every flag set by any source
+func (c Config) Names() []string {
+	seen := map[string]bool{}
+	names := []string{}
+
+	for _, source := range c.Sources {
+		for name := range source.Values {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// Lists, such as the retry statuses, are given as YAML sequences but the flags
+// expect a comma separated value
+func stringValues(values map[string]interface{}) map[string]string {
+	strs := map[string]string{}
+
+	for name, value := range values {
+		switch v := value.(type) {
+		case nil:
+			strs[name] = ""
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+
+			strs[name] = strings.Join(items, ",")
+		default:
+			strs[name] = fmt.Sprint(v)
+		}
+	}
+
+	return strs
+}
@@ -0,0 +1,151 @@
+package config_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/remove-bg/go/config"
+)
+
+// lookup a setting the config is expected to have
+func lookup(config Config, name string) Setting {
+	setting, ok := config.Lookup(name)
+	Expect(ok).To(BeTrue(), name)
+
+	return setting
+}
+
+var _ = Describe("Load", func() {
+	var (
+		tmpDir      string
+		userPath    string
+		projectPath string
+	)
+
+	write := func(path string, yaml string) {
+		Expect(ioutil.WriteFile(path, []byte(yaml), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "config-spec")
+		Expect(err).ToNot(HaveOccurred())
+
+		tmpDir = dir
+		userPath = filepath.Join(tmpDir, "config.yaml")
+		projectPath = filepath.Join(tmpDir, ProjectFileName)
+
+		write(userPath, `
+size: full
+concurrency: 4
+retry-statuses: [500, 503]
+profiles:
+  ecommerce:
+    type: product
+    bg-color: fff
+    output-directory: processed
+`)
+
+		os.Unsetenv(APIKeyEnv)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+		os.Unsetenv(APIKeyEnv)
+	})
+
+	It("reads the values and where they came from", func() {
+		config, err := Load(userPath, projectPath, "")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lookup(config, "size")).To(Equal(Setting{Value: "full", Source: userPath}))
+		Expect(lookup(config, "concurrency")).To(Equal(Setting{Value: "4", Source: userPath}))
+		Expect(lookup(config, "retry-statuses")).To(Equal(Setting{Value: "500,503", Source: userPath}))
+
+		_, ok := config.Lookup("type")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("prefers the project file over the user file", func() {
+		write(projectPath, "size: preview\n")
+
+		config, err := Load(userPath, projectPath, "")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lookup(config, "size")).To(Equal(Setting{Value: "preview", Source: projectPath}))
+		Expect(lookup(config, "concurrency")).To(Equal(Setting{Value: "4", Source: userPath}))
+	})
+
+	It("prefers the profile over either file", func() {
+		write(projectPath, "type: person\noutput-directory: out\n")
+
+		config, err := Load(userPath, projectPath, "ecommerce")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lookup(config, "type")).To(Equal(Setting{Value: "product", Source: "profile ecommerce (" + userPath + ")"}))
+		Expect(lookup(config, "output-directory")).To(Equal(Setting{Value: "processed", Source: "profile ecommerce (" + userPath + ")"}))
+		Expect(lookup(config, "size")).To(Equal(Setting{Value: "full", Source: userPath}))
+	})
+
+	It("merges a profile defined in both files", func() {
+		write(projectPath, "profiles:\n  ecommerce:\n    bg-color: '000'\n")
+
+		config, err := Load(userPath, projectPath, "ecommerce")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lookup(config, "bg-color")).To(Equal(Setting{Value: "000", Source: "profile ecommerce (" + projectPath + ")"}))
+		Expect(lookup(config, "type")).To(Equal(Setting{Value: "product", Source: "profile ecommerce (" + userPath + ")"}))
+	})
+
+	It("errors for an unknown profile", func() {
+		_, err := Load(userPath, projectPath, "missing")
+
+		Expect(err).To(MatchError("Unknown profile: missing"))
+	})
+
+	It("prefers the API key environment variable", func() {
+		write(projectPath, "api-key: from-file\n")
+		os.Setenv(APIKeyEnv, "from-env")
+
+		config, err := Load(userPath, projectPath, "")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lookup(config, "api-key")).To(Equal(Setting{Value: "from-env", Source: APIKeyEnv + " environment variable"}))
+	})
+
+	It("is empty without any config files", func() {
+		config, err := Load(filepath.Join(tmpDir, "missing.yaml"), projectPath, "")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.Names()).To(BeEmpty())
+	})
+
+	It("errors for invalid YAML", func() {
+		write(projectPath, "size: [full\n")
+
+		_, err := Load(userPath, projectPath, "")
+
+		Expect(err).To(MatchError(HavePrefix("Unable to read " + projectPath)))
+	})
+})
+
+var _ = Describe("UserPath", func() {
+	AfterEach(func() {
+		os.Unsetenv("XDG_CONFIG_HOME")
+	})
+
+	It("is within the XDG config directory", func() {
+		os.Setenv("XDG_CONFIG_HOME", "/xdg")
+
+		Expect(UserPath()).To(Equal("/xdg/removebg/config.yaml"))
+	})
+
+	It("defaults to ~/.config", func() {
+		home, err := os.UserHomeDir()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(UserPath()).To(Equal(filepath.Join(home, ".config", "removebg", "config.yaml")))
+	})
+})
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// CredentialsPath is the credentials store, next to the user config file
+func CredentialsPath() string {
+	return filepath.Join(filepath.Dir(UserPath()), "credentials")
+}
+
+// ReadKeys reads one API key per line, ignoring blank lines and # comments
+func ReadKeys(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	keys := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		keys = append(keys, line)
+	}
+
+	return keys, scanner.Err()
+}
+
+// AddKey to the credentials store, creating it readable only by the user
+func AddKey(path string, key string) error {
+	key = strings.TrimSpace(key)
+	if len(key) == 0 || strings.ContainsAny(key, " \t\r\n#") {
+		return errors.New("Invalid API key")
+	}
+
+	keys, err := ReadKeys(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, existing := range keys {
+		if existing == key {
+			return nil
+		}
+	}
+
+	return writeKeys(path, append(keys, key))
+}
+
+// RemoveKey from the credentials store, numbered from 1 as listed
+func RemoveKey(path string, number int) error {
+	keys, err := ReadKeys(path)
+	if err != nil {
+		return err
+	}
+
+	if number < 1 || number > len(keys) {
+		return fmt.Errorf("No key %d, there are %d keys", number, len(keys))
+	}
+
+	return writeKeys(path, append(keys[:number-1], keys[number:]...))
+}
+
+// The file is written to a temporary file first, so it's never readable by
+// others or left truncated
+func writeKeys(path string, keys []string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".credentials.*.tmp")
+	if err != nil {
+		return err
+	}
+
+	data := bytes.Buffer{}
+	for _, key := range keys {
+		data.WriteString(key + "\n")
+	}
+
+	_, err = tmp.Write(data.Bytes())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0600)
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+
+	return err
+}
+
+// ReadableByOthers is true if the file's group or other permissions allow
+// reading it. Windows doesn't have these permissions.
+func ReadableByOthers(path string) bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return info.Mode().Perm()&0044 != 0
+}
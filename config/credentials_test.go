@@ -0,0 +1,104 @@
+package config_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/remove-bg/go/config"
+)
+
+var _ = Describe("Credentials", func() {
+	var (
+		tmpDir string
+		path   string
+	)
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "credentials-spec")
+		Expect(err).ToNot(HaveOccurred())
+
+		tmpDir = dir
+		path = filepath.Join(tmpDir, "removebg", "credentials")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("is next to the user config file", func() {
+		Expect(CredentialsPath()).To(Equal(filepath.Join(filepath.Dir(UserPath()), "credentials")))
+	})
+
+	Describe("AddKey", func() {
+		It("creates the store readable only by the user", func() {
+			Expect(AddKey(path, "key-1")).To(Succeed())
+
+			info, err := os.Stat(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+			dirInfo, err := os.Stat(filepath.Dir(path))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dirInfo.Mode().Perm()).To(Equal(os.FileMode(0700)))
+			Expect(ReadableByOthers(path)).To(BeFalse())
+		})
+
+		It("appends keys, ignoring ones already stored", func() {
+			Expect(AddKey(path, "key-1")).To(Succeed())
+			Expect(AddKey(path, " key-2\n")).To(Succeed())
+			Expect(AddKey(path, "key-1")).To(Succeed())
+
+			Expect(ReadKeys(path)).To(Equal([]string{"key-1", "key-2"}))
+		})
+
+		It("rejects invalid keys", func() {
+			Expect(AddKey(path, "")).To(MatchError("Invalid API key"))
+			Expect(AddKey(path, "key 1")).To(MatchError("Invalid API key"))
+		})
+	})
+
+	Describe("ReadKeys", func() {
+		It("ignores blank lines and comments", func() {
+			keysPath := filepath.Join(tmpDir, "keys")
+			Expect(ioutil.WriteFile(keysPath, []byte("# Production\nkey-1\n\n  key-2  \n"), 0600)).To(Succeed())
+
+			Expect(ReadKeys(keysPath)).To(Equal([]string{"key-1", "key-2"}))
+		})
+
+		It("errors if the file doesn't exist", func() {
+			_, err := ReadKeys(path)
+
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+
+	Describe("RemoveKey", func() {
+		BeforeEach(func() {
+			Expect(AddKey(path, "key-1")).To(Succeed())
+			Expect(AddKey(path, "key-2")).To(Succeed())
+		})
+
+		It("removes the numbered key", func() {
+			Expect(RemoveKey(path, 1)).To(Succeed())
+
+			Expect(ReadKeys(path)).To(Equal([]string{"key-2"}))
+		})
+
+		It("errors for a key which doesn't exist", func() {
+			Expect(RemoveKey(path, 3)).To(MatchError("No key 3, there are 2 keys"))
+		})
+	})
+
+	Describe("ReadableByOthers", func() {
+		It("is true when the group or others can read the file", func() {
+			keysPath := filepath.Join(tmpDir, "keys")
+			Expect(ioutil.WriteFile(keysPath, []byte("key-1\n"), 0644)).To(Succeed())
+			Expect(os.Chmod(keysPath, 0644)).To(Succeed())
+
+			Expect(ReadableByOthers(keysPath)).To(BeTrue())
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -15,9 +16,12 @@ var (
 func main() {
 	cmd.ConfigureVersion(version, commit)
 
-	err := cmd.RootCmd.Execute()
+	ctx, cancel := cmd.CancelOnSignal(context.Background())
+	defer cancel()
+
+	err := cmd.RootCmd.ExecuteContext(ctx)
 	if err != nil {
-		fmt.Printf("Error: %s\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"github.com/remove-bg/go/composite"
+	"strings"
+)
+
+// localBackground is applied by the compositor rather than the API, so the
+// background image is never uploaded
+func (is ImageSettings) localBackground() bool {
+	return len(is.BgColor) > 0 || len(is.BgImageFile) > 0
+}
+
+// compositeOptions built in advance if they were given, so the background
+// image isn't decoded again
+func (s Settings) compositeOptions() (composite.Options, error) {
+	if s.CompositeOptions != nil {
+		return *s.CompositeOptions, nil
+	}
+
+	return s.ImageSettings.CompositeOptions()
+}
+
+// CompositeOptions for the image settings, decoding any background image
+func (is ImageSettings) CompositeOptions() (composite.Options, error) {
+	options := composite.Options{Format: composite.FormatPng}
+
+	// Only composited without a background when caching masks, but the format
+	// applies either way
+	format := strings.ToLower(is.OutputFormat)
+	if format == "jpg" || format == "jpeg" {
+		options.Format = composite.FormatJpg
+	}
+
+	if !is.localBackground() {
+		return options, nil
+	}
+
+	options.Background.Mode = composite.BackgroundFill
+
+	if len(is.BgMode) > 0 {
+		mode, err := composite.ParseBackgroundMode(is.BgMode)
+		if err != nil {
+			return options, err
+		}
+
+		options.Background.Mode = mode
+	}
+
+	if len(is.BgColor) > 0 {
+		color, err := composite.ParseColor(is.BgColor)
+		if err != nil {
+			return options, err
+		}
+
+		options.Background.Color = &color
+	}
+
+	if len(is.BgImageFile) > 0 {
+		img, err := composite.DecodeImageFile(is.BgImageFile)
+		if err != nil {
+			return options, err
+		}
+
+		options.Background.Image = img
+	}
+
+	return options, nil
+}
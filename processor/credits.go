@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"strings"
+	"sync"
+)
+
+// CreditEstimate is the expected cost of a batch, alongside the account
+// balance if it could be fetched
+type CreditEstimate struct {
+	Credits      float64
+	Balance      float64
+	BalanceKnown bool
+}
+
+// Lower resolution sizes are charged at a fraction of a credit
+var fractionalCreditSizes = map[string]float64{
+	"preview": 0.25,
+	"small":   0.25,
+	"regular": 0.25,
+}
+
+// EstimateCredits per image for the requested size. Sizes which depend on
+// the input resolution (e.g. auto) assume the full cost.
+func EstimateCredits(size string) float64 {
+	if credits, ok := fractionalCreditSizes[strings.ToLower(size)]; ok {
+		return credits
+	}
+
+	return 1
+}
+
+// creditBudget reserves the expected cost of each image before it's sent,
+// and settles up with the credits actually charged once it completes. The
+// cost is expected to be the largest charge seen so far, falling back to the
+// estimate until the first image has been charged.
+type creditBudget struct {
+	max      float64
+	estimate float64
+	mutex    sync.Mutex
+	spent    float64
+	reserved float64
+	charged  bool
+	largest  float64
+}
+
+// reserve returns the credits reserved, to be settled once the image completes
+func (b *creditBudget) reserve() (float64, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	expected := b.estimate
+	if b.charged {
+		expected = b.largest
+	}
+
+	if b.max > 0 && b.spent+b.reserved+expected > b.max {
+		return 0, false
+	}
+
+	b.reserved += expected
+	return expected, true
+}
+
+// settle the reservation. Only successful images give the per image cost, as
+// failed ones aren't charged.
+func (b *creditBudget) settle(reserved float64, charged float64, succeeded bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.reserved -= reserved
+	b.spent += charged
+
+	if succeeded && (!b.charged || charged > b.largest) {
+		b.charged = true
+		b.largest = charged
+	}
+}
@@ -0,0 +1,22 @@
+package processor_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/remove-bg/go/processor"
+)
+
+var _ = Describe("EstimateCredits", func() {
+	It("charges a fraction of a credit for low resolution sizes", func() {
+		Expect(EstimateCredits("preview")).To(Equal(0.25))
+		Expect(EstimateCredits("small")).To(Equal(0.25))
+		Expect(EstimateCredits("Regular")).To(Equal(0.25))
+	})
+
+	It("charges a full credit for other sizes", func() {
+		Expect(EstimateCredits("full")).To(Equal(1.0))
+		Expect(EstimateCredits("auto")).To(Equal(1.0))
+		Expect(EstimateCredits("")).To(Equal(1.0))
+	})
+})
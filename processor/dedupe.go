@@ -0,0 +1,201 @@
+package processor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/remove-bg/go/client"
+	"sort"
+	"sync"
+)
+
+// dedupe tracks the first image with each content hash, so byte-identical
+// images under different names are only sent to the API once
+type dedupe struct {
+	mutex     sync.Mutex
+	originals map[string]*original
+}
+
+// original is the image a duplicate is copied from. done is closed once it
+// has been processed, after which the outcome is set. batch is nil for images
+// a previous batch processed.
+type original struct {
+	input   string
+	output  string
+	batch   *batch
+	outcome imageOutcome
+	done    chan struct{}
+}
+
+// DedupeIndex keeps the originals between calls to Process, so a long running
+// caller such as watch, which processes one image at a time, only reads the
+// journal once rather than for every image
+type DedupeIndex struct {
+	mutex  sync.Mutex
+	dedupe *dedupe
+}
+
+func NewDedupeIndex() *DedupeIndex {
+	return &DedupeIndex{}
+}
+
+func (i *DedupeIndex) seeded() bool {
+	if i == nil {
+		return false
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.dedupe != nil
+}
+
+// newDedupe, or the shared index's if it has already been seeded
+func (p Processor) newDedupe(settings Settings, journaled map[string]JournalEntry, b *batch) *dedupe {
+	if !settings.Dedupe {
+		return nil
+	}
+
+	if p.DedupeIndex == nil {
+		return p.seedDedupe(journaled, b)
+	}
+
+	p.DedupeIndex.mutex.Lock()
+	defer p.DedupeIndex.mutex.Unlock()
+
+	if p.DedupeIndex.dedupe == nil {
+		p.DedupeIndex.dedupe = p.seedDedupe(journaled, b)
+	}
+
+	return p.DedupeIndex.dedupe
+}
+
+// seedDedupe with the images a previous batch processed with the same
+// settings, as long as their output still exists
+func (p Processor) seedDedupe(journaled map[string]JournalEntry, b *batch) *dedupe {
+	d := &dedupe{originals: map[string]*original{}}
+
+	for _, entry := range journaled {
+		if entry.Status != JournalProcessed || len(entry.InputHash) == 0 || entry.Settings != b.fingerprint {
+			continue
+		}
+
+		if !p.Storage.FileExists(b.writtenPath(entry.Output)) {
+			continue
+		}
+
+		processed := &original{
+			input:   entry.Input,
+			output:  entry.Output,
+			outcome: imageOutcome{result: imageProcessed, credits: entry.Credits, output: entry.Output},
+			done:    make(chan struct{}),
+		}
+
+		close(processed.done)
+		d.originals[entry.InputHash] = processed
+	}
+
+	return d
+}
+
+// claim the hash for the image, returning true if it's the first with that
+// hash and so should be processed. Otherwise the original is returned.
+func (d *dedupe) claim(planned PlannedImage, b *batch) (*original, bool) {
+	if d == nil || len(planned.InputHash) == 0 {
+		return nil, true
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if existing, ok := d.originals[planned.InputHash]; ok && existing.reusableBy(planned, b) {
+		return existing, false
+	}
+
+	claimed := &original{input: planned.Input, output: planned.Output, batch: b, done: make(chan struct{})}
+	d.originals[planned.InputHash] = claimed
+
+	return claimed, true
+}
+
+// reusableBy the image, always within a batch. A previous batch's output isn't
+// reused for the same image or for an output being overwritten, as the image
+// would never reach the API.
+func (o *original) reusableBy(planned PlannedImage, b *batch) bool {
+	if o.batch == b {
+		return true
+	}
+
+	return planned.Action != PlanOverwrite && o.input != planned.Input
+}
+
+// finish releases any duplicates waiting on the original
+func (o *original) finish(outcome imageOutcome) {
+	if o == nil {
+		return
+	}
+
+	o.outcome = outcome
+	close(o.done)
+}
+
+// copyDuplicate waits for the original to be processed, then copies its
+// output. If the original wasn't processed the duplicate is processed itself.
+func (p Processor) copyDuplicate(ctx context.Context, j job, b *batch, planned PlannedImage, o *original) imageOutcome {
+	select {
+	case <-o.done:
+	case <-b.control.done:
+	case <-ctx.Done():
+	}
+
+	if b.control.halted() {
+		return imageOutcome{result: imageNotStarted, output: planned.Output}
+	}
+
+	if o.outcome.result != imageProcessed {
+		return p.removeBackground(ctx, j, b, planned)
+	}
+
+	outcome := imageOutcome{result: imageProcessed, output: planned.Output, duplicate: true, saved: o.outcome.credits}
+
+	source, destination := b.writtenPath(o.output), b.writtenPath(planned.Output)
+	if source != destination {
+		outcome.err = p.Storage.Copy(source, destination)
+	}
+
+	p.recordJournal(planned, b.fingerprint, client.Result{}, outcome.err)
+
+	if outcome.err != nil {
+		p.Notifier.Error(outcome.err, j.inputPath, planned.Output, j.imageNumber, b.totalImages)
+		outcome.result = imageFailed
+		return outcome
+	}
+
+	p.Notifier.Duplicate(j.inputPath, planned.Output, o.input, j.imageNumber, b.totalImages)
+	return outcome
+}
+
+// fingerprint identifies the settings which affect the output, so a previous
+// batch's output is only reused if it would be identical
+func (is ImageSettings) fingerprint() string {
+	params := imageSettingsToParams(is)
+	params["bg_color"] = is.BgColor
+	params["bg_image_file"] = is.BgImageFile
+	params["bg_mode"] = is.BgMode
+	params["output_format"] = is.OutputFormat
+
+	keys := []string{}
+	for key := range params {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%s\n", key, params[key])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -1,17 +1,35 @@
 package processor
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
 	"path"
 	"path/filepath"
 	"strings"
 )
 
 const defaultOutputExtension = ".png"
+const defaultURLFileName = "image"
 
 func DetermineOutputPath(inputPath string, settings Settings) string {
+	return outputPath(inputPath, "", settings)
+}
+
+// outputPath of the input, disambiguated if it's a URL which would collide
+func (s Settings) outputPath(inputPath string) string {
+	if output, ok := s.outputPaths[inputPath]; ok {
+		return output
+	}
+
+	return DetermineOutputPath(inputPath, s)
+}
+
+// outputPath with the suffix added to the file name, before any -removebg
+func outputPath(inputPath string, suffix string, settings Settings) string {
 	outputDirectory := settings.OutputDirectory
-	inputDirectory, fileName := filepath.Split(inputPath)
-	extensionlessFileName := strings.TrimSuffix(fileName, path.Ext(fileName))
+	inputDirectory, fileName := splitInputPath(inputPath)
+	extensionlessFileName := strings.TrimSuffix(fileName, path.Ext(fileName)) + suffix
 	outputExtension := defaultOutputExtension
 
 	if len(settings.ImageSettings.OutputFormat) > 0 {
@@ -24,3 +42,57 @@ func DetermineOutputPath(inputPath string, settings Settings) string {
 
 	return filepath.Join(outputDirectory, extensionlessFileName+outputExtension)
 }
+
+// disambiguateURLOutputs names URLs whose output would collide with another
+// input's after a short hash of the URL as well, e.g. main-1a2b3c4d.png, as
+// CDNs often use the same file name in different directories
+func disambiguateURLOutputs(inputPaths []string, settings Settings) map[string]string {
+	inputsByOutput := map[string]map[string]bool{}
+	for _, inputPath := range inputPaths {
+		output := DetermineOutputPath(inputPath, settings)
+		if inputsByOutput[output] == nil {
+			inputsByOutput[output] = map[string]bool{}
+		}
+
+		inputsByOutput[output][inputPath] = true
+	}
+
+	outputs := map[string]string{}
+	for _, inputs := range inputsByOutput {
+		if len(inputs) < 2 {
+			continue
+		}
+
+		for inputPath := range inputs {
+			if IsURL(inputPath) {
+				hash := sha256.Sum256([]byte(inputPath))
+				outputs[inputPath] = outputPath(inputPath, "-"+hex.EncodeToString(hash[:4]), settings)
+			}
+		}
+	}
+
+	return outputs
+}
+
+func IsURL(inputPath string) bool {
+	return strings.HasPrefix(inputPath, "http://") || strings.HasPrefix(inputPath, "https://")
+}
+
+// URLs are saved to the current directory, named after the last path segment
+func splitInputPath(inputPath string) (string, string) {
+	if !IsURL(inputPath) {
+		return filepath.Split(inputPath)
+	}
+
+	fileName := defaultURLFileName
+	parsed, err := url.Parse(inputPath)
+
+	if err == nil {
+		base := path.Base(parsed.Path)
+		if base != "/" && base != "." {
+			fileName = base
+		}
+	}
+
+	return "", fileName
+}
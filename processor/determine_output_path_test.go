@@ -46,4 +46,36 @@ var _ = Describe("DetermineOutputPath", func() {
 			Expect(result).To(Equal("out/image.jpg"))
 		})
 	})
+
+	Context("when the input is a URL", func() {
+		It("names the output after the URL path", func() {
+			settings := Settings{
+				OutputDirectory: "out",
+			}
+
+			result := DetermineOutputPath("https://cdn.example/products/a.jpg?w=500", settings)
+
+			Expect(result).To(Equal("out/a.png"))
+		})
+
+		It("writes to the current directory with a filename suffix", func() {
+			settings := Settings{
+				OutputDirectory: "",
+			}
+
+			result := DetermineOutputPath("https://cdn.example/products/a.jpg", settings)
+
+			Expect(result).To(Equal("a-removebg.png"))
+		})
+
+		It("falls back to a default filename", func() {
+			settings := Settings{
+				OutputDirectory: "out",
+			}
+
+			result := DetermineOutputPath("https://cdn.example/", settings)
+
+			Expect(result).To(Equal("out/image.png"))
+		})
+	})
 })
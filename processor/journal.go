@@ -0,0 +1,170 @@
+package processor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/remove-bg/go/client"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalFileName is written to the output directory, recording the outcome
+// of every image so an interrupted batch can be resumed
+const JournalFileName = ".removebg-journal.jsonl"
+
+type JournalStatus string
+
+const (
+	JournalProcessed JournalStatus = "processed"
+	JournalFailed    JournalStatus = "failed"
+)
+
+type JournalEntry struct {
+	Input     string        `json:"input"`
+	Output    string        `json:"output"`
+	InputHash string        `json:"input_hash,omitempty"`
+	Settings  string        `json:"settings,omitempty"` // Fingerprint of the image settings
+	Status    JournalStatus `json:"status"`
+	Credits   float64       `json:"credits"`
+	Error     string        `json:"error,omitempty"`
+	Time      time.Time     `json:"time"`
+}
+
+//go:generate counterfeiter . JournalInterface
+type JournalInterface interface {
+	Load() (map[string]JournalEntry, error)
+	Record(entry JournalEntry) error
+}
+
+// FileJournal appends one JSON entry per line, so it survives the process
+// being killed part way through a batch
+type FileJournal struct {
+	Path  string
+	mutex *sync.Mutex
+}
+
+func NewFileJournal(path string) FileJournal {
+	return FileJournal{
+		Path:  path,
+		mutex: &sync.Mutex{},
+	}
+}
+
+// Load the latest entry for each input. A missing journal is empty.
+func (j FileJournal) Load() (map[string]JournalEntry, error) {
+	entries := map[string]JournalEntry{}
+
+	file, err := os.Open(j.Path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := JournalEntry{}
+
+		// Skip any line truncated by the process being killed mid-write
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+
+		entries[entry.Input] = entry
+	}
+
+	return entries, scanner.Err()
+}
+
+func (j FileJournal) Record(entry JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	file, err := os.OpenFile(j.Path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+
+	// A line truncated by the process being killed is ended first, or the
+	// entry would be lost along with it
+	terminated, err := endsWithNewline(file)
+	if err == nil && !terminated {
+		line = append([]byte{'\n'}, line...)
+	}
+
+	if err == nil {
+		_, err = file.Write(append(line, '\n'))
+	}
+
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// endsWithNewline is also true for an empty file
+func endsWithNewline(file *os.File) (bool, error) {
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return true, err
+	}
+
+	last := make([]byte, 1)
+	_, err = file.ReadAt(last, info.Size()-1)
+
+	return last[0] == '\n', err
+}
+
+// Previous outcomes are only needed when resuming, or to copy the outputs of
+// identical images unless a shared index already has them
+func (p Processor) loadJournal(settings Settings) (map[string]JournalEntry, error) {
+	dedupe := settings.Dedupe && !p.DedupeIndex.seeded()
+
+	if p.Journal == nil || !(settings.Resume || dedupe) {
+		return map[string]JournalEntry{}, nil
+	}
+
+	return p.Journal.Load()
+}
+
+func (p Processor) recordJournal(planned PlannedImage, fingerprint string, result client.Result, err error) {
+	if p.Journal == nil {
+		return
+	}
+
+	entry := JournalEntry{
+		Input:     planned.Input,
+		Output:    planned.Output,
+		InputHash: planned.InputHash,
+		Settings:  fingerprint,
+		Status:    JournalProcessed,
+		Credits:   result.CreditsCharged,
+		Time:      time.Now().UTC(),
+	}
+
+	if err != nil {
+		entry.Status = JournalFailed
+		entry.Error = err.Error()
+	}
+
+	recordErr := p.Journal.Record(entry)
+	if recordErr != nil {
+		fmt.Fprintf(os.Stderr, "Unable to record journal entry: %s\n", recordErr)
+	}
+}
+
+// An input is only complete if it hasn't changed since it was processed
+func (e JournalEntry) completed(inputHash string) bool {
+	return e.Status == JournalProcessed && e.InputHash == inputHash
+}
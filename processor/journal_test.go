@@ -0,0 +1,82 @@
+package processor_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/remove-bg/go/processor"
+)
+
+var _ = Describe("FileJournal", func() {
+	var (
+		tmpDir  string
+		subject FileJournal
+	)
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "journal-spec")
+		Expect(err).ToNot(HaveOccurred())
+
+		tmpDir = dir
+		subject = NewFileJournal(filepath.Join(tmpDir, JournalFileName))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("is empty if the journal doesn't exist", func() {
+		entries, err := subject.Load()
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("loads the recorded entries", func() {
+		entry := JournalEntry{
+			Input:     "dir/image1.jpg",
+			Output:    "out/image1.png",
+			InputHash: "abc123",
+			Status:    JournalProcessed,
+			Credits:   1,
+			Time:      time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		}
+
+		Expect(subject.Record(entry)).To(Succeed())
+
+		entries, err := subject.Load()
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(Equal(map[string]JournalEntry{"dir/image1.jpg": entry}))
+	})
+
+	It("keeps the latest entry for each input", func() {
+		Expect(subject.Record(JournalEntry{Input: "dir/image1.jpg", Status: JournalFailed})).To(Succeed())
+		Expect(subject.Record(JournalEntry{Input: "dir/image1.jpg", Status: JournalProcessed})).To(Succeed())
+
+		entries, err := subject.Load()
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries["dir/image1.jpg"].Status).To(Equal(JournalProcessed))
+	})
+
+	It("ignores truncated lines, recording later entries on a new line", func() {
+		Expect(subject.Record(JournalEntry{Input: "dir/image1.jpg", Status: JournalProcessed})).To(Succeed())
+
+		file, err := os.OpenFile(subject.Path, os.O_APPEND|os.O_WRONLY, 0644)
+		Expect(err).ToNot(HaveOccurred())
+		file.WriteString(`{"input": "dir/ima`)
+		file.Close()
+
+		Expect(subject.Record(JournalEntry{Input: "dir/image2.jpg", Status: JournalProcessed})).To(Succeed())
+		entries, err := subject.Load()
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+		Expect(entries).To(HaveKey("dir/image2.jpg"))
+	})
+})
@@ -0,0 +1,96 @@
+package processor
+
+import (
+	"github.com/remove-bg/go/client"
+	"github.com/sirupsen/logrus"
+	"io"
+	"time"
+)
+
+// JSONNotifier writes one JSON object per line for each event, for log
+// aggregation
+type JSONNotifier struct {
+	Logger *logrus.Logger
+}
+
+func NewJSONNotifier(w io.Writer) JSONNotifier {
+	l := logrus.New()
+	l.SetOutput(w)
+	l.SetFormatter(&logrus.JSONFormatter{})
+
+	return JSONNotifier{
+		Logger: l,
+	}
+}
+
+func (n JSONNotifier) Success(input string, output string, result client.Result, imageNumber int, totalImages int) {
+	fields := imageFields("success", input, output, imageNumber, totalImages)
+	fields["credits"] = result.CreditsCharged
+	fields["duration_ms"] = result.Duration.Milliseconds()
+
+	if result.Width > 0 && result.Height > 0 {
+		fields["width"] = result.Width
+		fields["height"] = result.Height
+	}
+
+	if len(result.DetectedType) > 0 {
+		fields["type"] = result.DetectedType
+	}
+
+	n.Logger.WithFields(fields).Info("Processed image")
+}
+
+func (n JSONNotifier) Skip(input string, existing string, imageNumber int, totalImages int) {
+	n.Logger.WithFields(imageFields("skip", input, existing, imageNumber, totalImages)).Warn("Skipped image")
+}
+
+func (n JSONNotifier) Duplicate(input string, output string, original string, imageNumber int, totalImages int) {
+	fields := imageFields("duplicate", input, output, imageNumber, totalImages)
+	fields["original"] = original
+
+	n.Logger.WithFields(fields).Info("Copied duplicate image")
+}
+
+func (n JSONNotifier) Error(err error, input string, output string, imageNumber int, totalImages int) {
+	fields := imageFields("error", input, output, imageNumber, totalImages)
+	addRequestErrorFields(fields, err)
+
+	n.Logger.WithFields(fields).WithError(err).Error("Failed image")
+}
+
+func (n JSONNotifier) Retry(err error, input string, attempt int, delay time.Duration, imageNumber int, totalImages int) {
+	fields := imageFields("retry", input, "", imageNumber, totalImages)
+	fields["attempt"] = attempt
+	fields["delay_ms"] = delay.Milliseconds()
+	addRequestErrorFields(fields, err)
+
+	n.Logger.WithFields(fields).WithError(err).Warn("Retrying image")
+}
+
+func imageFields(event string, input string, output string, imageNumber int, totalImages int) logrus.Fields {
+	fields := logrus.Fields{
+		"event": event,
+		"input": input,
+		"index": imageNumber,
+		"total": totalImages,
+	}
+
+	if len(output) > 0 {
+		fields["output"] = output
+	}
+
+	return fields
+}
+
+func addRequestErrorFields(fields logrus.Fields, err error) {
+	clientErr, ok := err.(*client.RequestError)
+	if !ok {
+		return
+	}
+
+	fields["status"] = clientErr.StatusCode
+
+	if len(clientErr.Code) > 0 {
+		fields["code"] = clientErr.Code
+	}
+}
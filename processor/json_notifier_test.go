@@ -0,0 +1,114 @@
+package processor_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/remove-bg/go/client"
+	"time"
+
+	. "github.com/remove-bg/go/processor"
+)
+
+var _ = Describe("JSONNotifier", func() {
+	var (
+		out     *bytes.Buffer
+		subject JSONNotifier
+	)
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		subject = NewJSONNotifier(out)
+	})
+
+	lastEvent := func() map[string]interface{} {
+		lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+		event := map[string]interface{}{}
+		Expect(json.Unmarshal(lines[len(lines)-1], &event)).To(Succeed())
+		return event
+	}
+
+	It("writes one line per event", func() {
+		subject.Skip("input/a.jpg", "output/a.png", 1, 2)
+		subject.Skip("input/b.jpg", "output/b.png", 2, 2)
+
+		Expect(bytes.Count(out.Bytes(), []byte("\n"))).To(Equal(2))
+	})
+
+	It("logs successes with the response metadata and timing", func() {
+		result := client.Result{
+			CreditsCharged: 0.25,
+			Width:          640,
+			Height:         480,
+			DetectedType:   "person",
+			Duration:       1500 * time.Millisecond,
+		}
+
+		subject.Success("input/image.jpg", "output/image.png", result, 1, 2)
+
+		event := lastEvent()
+		Expect(event["event"]).To(Equal("success"))
+		Expect(event["input"]).To(Equal("input/image.jpg"))
+		Expect(event["output"]).To(Equal("output/image.png"))
+		Expect(event["index"]).To(BeEquivalentTo(1))
+		Expect(event["total"]).To(BeEquivalentTo(2))
+		Expect(event["credits"]).To(BeEquivalentTo(0.25))
+		Expect(event["width"]).To(BeEquivalentTo(640))
+		Expect(event["height"]).To(BeEquivalentTo(480))
+		Expect(event["type"]).To(Equal("person"))
+		Expect(event["duration_ms"]).To(BeEquivalentTo(1500))
+	})
+
+	It("logs skips", func() {
+		subject.Skip("input/image.jpg", "output/image.png", 1, 2)
+
+		event := lastEvent()
+		Expect(event["event"]).To(Equal("skip"))
+		Expect(event["output"]).To(Equal("output/image.png"))
+	})
+
+	It("logs duplicates with their original", func() {
+		subject.Duplicate("input/copy.jpg", "output/copy.png", "input/image.jpg", 2, 2)
+
+		event := lastEvent()
+		Expect(event["event"]).To(Equal("duplicate"))
+		Expect(event["output"]).To(Equal("output/copy.png"))
+		Expect(event["original"]).To(Equal("input/image.jpg"))
+	})
+
+	It("logs the HTTP status and error code of API errors", func() {
+		err := &client.RequestError{StatusCode: 400, Code: "unknown_foreground", Err: errors.New("Could not identify foreground")}
+
+		subject.Error(err, "input/image.jpg", "output/image.png", 1, 2)
+
+		event := lastEvent()
+		Expect(event["event"]).To(Equal("error"))
+		Expect(event["level"]).To(Equal("error"))
+		Expect(event["status"]).To(BeEquivalentTo(400))
+		Expect(event["code"]).To(Equal("unknown_foreground"))
+		Expect(event["error"]).To(Equal("400: Could not identify foreground"))
+	})
+
+	It("logs other errors without a status", func() {
+		subject.Error(errors.New("boom"), "input/image.jpg", "output/image.png", 1, 2)
+
+		event := lastEvent()
+		Expect(event["error"]).To(Equal("boom"))
+		Expect(event).ToNot(HaveKey("status"))
+	})
+
+	It("logs retries with the delay", func() {
+		err := &client.RequestError{StatusCode: 503, Err: errors.New("Unable to process image")}
+
+		subject.Retry(err, "input/image.jpg", 2, 30*time.Second, 1, 2)
+
+		event := lastEvent()
+		Expect(event["event"]).To(Equal("retry"))
+		Expect(event["attempt"]).To(BeEquivalentTo(2))
+		Expect(event["delay_ms"]).To(BeEquivalentTo(30000))
+		Expect(event["status"]).To(BeEquivalentTo(503))
+	})
+})
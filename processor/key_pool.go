@@ -0,0 +1,68 @@
+package processor
+
+import (
+	"github.com/remove-bg/go/client"
+	"sync"
+)
+
+// KeyPool is shared by every worker, moving on to the next API key when the
+// current one runs out of credits or is rate limited
+type KeyPool struct {
+	mutex        sync.Mutex
+	keys         []string
+	current      int
+	outOfCredits map[string]bool // For the rest of the batch
+	rateLimited  map[string]bool // Until every key has been rate limited
+}
+
+func NewKeyPool(keys []string) *KeyPool {
+	return &KeyPool{
+		keys:         keys,
+		outOfCredits: map[string]bool{},
+		rateLimited:  map[string]bool{},
+	}
+}
+
+// Current is the key to make requests with
+func (k *KeyPool) Current() string {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	return k.keys[k.current]
+}
+
+// Rotate away from a key after the request failed with it, returning true if
+// the request should be retried with the new current key. Once every key is
+// rate limited it returns false, so the usual rate limit back off applies
+// before they're tried again.
+func (k *KeyPool) Rotate(failed string, err error) bool {
+	clientErr, ok := err.(*client.RequestError)
+	if !ok || !(clientErr.OutOfCredits() || clientErr.RateLimitExceeded()) {
+		return false
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if clientErr.OutOfCredits() {
+		k.outOfCredits[failed] = true
+	} else {
+		k.rateLimited[failed] = true
+	}
+
+	// Another worker may have already rotated away from the failed key
+	for i := 0; i < len(k.keys); i++ {
+		index := (k.current + i) % len(k.keys)
+		if k.usable(k.keys[index]) {
+			k.current = index
+			return true
+		}
+	}
+
+	k.rateLimited = map[string]bool{}
+	return false
+}
+
+func (k *KeyPool) usable(key string) bool {
+	return !k.outOfCredits[key] && !k.rateLimited[key]
+}
@@ -0,0 +1,72 @@
+package processor_test
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/client"
+
+	. "github.com/remove-bg/go/processor"
+)
+
+var _ = Describe("KeyPool", func() {
+	var (
+		subject      *KeyPool
+		outOfCredits = &client.RequestError{StatusCode: 402, Err: errors.New("Insufficient credits")}
+		rateLimited  = &client.RequestError{StatusCode: 429, Err: errors.New("Rate limit exceeded")}
+	)
+
+	BeforeEach(func() {
+		subject = NewKeyPool([]string{"key-1", "key-2", "key-3"})
+	})
+
+	It("starts with the first key", func() {
+		Expect(subject.Current()).To(Equal("key-1"))
+	})
+
+	It("rotates to the next key when out of credits or rate limited", func() {
+		Expect(subject.Rotate("key-1", outOfCredits)).To(BeTrue())
+		Expect(subject.Current()).To(Equal("key-2"))
+
+		Expect(subject.Rotate("key-2", rateLimited)).To(BeTrue())
+		Expect(subject.Current()).To(Equal("key-3"))
+	})
+
+	It("doesn't rotate for other errors", func() {
+		Expect(subject.Rotate("key-1", &client.RequestError{StatusCode: 400, Err: errors.New("Bad request")})).To(BeFalse())
+		Expect(subject.Rotate("key-1", errors.New("network error"))).To(BeFalse())
+		Expect(subject.Current()).To(Equal("key-1"))
+	})
+
+	It("doesn't rotate again when another request already did", func() {
+		subject.Rotate("key-1", rateLimited)
+
+		Expect(subject.Rotate("key-1", rateLimited)).To(BeTrue())
+		Expect(subject.Current()).To(Equal("key-2"))
+	})
+
+	It("tries rate limited keys again once every key is rate limited", func() {
+		subject.Rotate("key-1", rateLimited)
+		subject.Rotate("key-2", rateLimited)
+
+		Expect(subject.Rotate("key-3", rateLimited)).To(BeFalse())
+		Expect(subject.Rotate("key-3", rateLimited)).To(BeTrue())
+		Expect(subject.Current()).To(Equal("key-1"))
+	})
+
+	It("never returns to a key which is out of credits", func() {
+		subject.Rotate("key-1", outOfCredits)
+		subject.Rotate("key-2", rateLimited)
+		Expect(subject.Rotate("key-3", rateLimited)).To(BeFalse())
+
+		Expect(subject.Rotate("key-3", rateLimited)).To(BeTrue())
+		Expect(subject.Current()).To(Equal("key-2"))
+	})
+
+	It("gives up once every key is out of credits", func() {
+		subject.Rotate("key-1", outOfCredits)
+		subject.Rotate("key-2", outOfCredits)
+
+		Expect(subject.Rotate("key-3", outOfCredits)).To(BeFalse())
+	})
+})
@@ -0,0 +1,114 @@
+package processor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/remove-bg/go/client"
+	"github.com/remove-bg/go/composite"
+	"github.com/remove-bg/go/storage"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrMaskNotCached is returned when an image hasn't been processed with the
+// same API parameters while caching masks
+var ErrMaskNotCached = errors.New("No cached mask, process the image with --cache-masks first")
+
+//go:generate counterfeiter . MaskCacheInterface
+type MaskCacheInterface interface {
+	Get(key string) ([]byte, error)
+	Put(key string, mask []byte) error
+}
+
+// FileMaskCache stores each alpha mask as a PNG named after its key
+type FileMaskCache struct {
+	Directory string
+	Storage   storage.StorageInterface
+}
+
+func NewFileMaskCache(directory string) FileMaskCache {
+	return FileMaskCache{
+		Directory: directory,
+		Storage:   storage.FileStorage{},
+	}
+}
+
+// DefaultMaskCacheDirectory is within the user's cache directory, falling back
+// to the current directory
+func DefaultMaskCacheDirectory() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ".removebg-masks"
+	}
+
+	return filepath.Join(dir, "removebg", "masks")
+}
+
+// MaskCacheKey identifies the mask for an input's contents and the API
+// parameters which affect it. The format is ignored, as it only changes how
+// the result is transferred.
+func MaskCacheKey(inputHash string, params map[string]string) string {
+	keys := []string{}
+	for key := range params {
+		if key != "format" {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n", inputHash)
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%s\n", key, params[key])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (c FileMaskCache) Get(key string) ([]byte, error) {
+	mask, err := ioutil.ReadFile(c.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrMaskNotCached
+	}
+
+	return mask, err
+}
+
+// Put writes atomically, as parallel workers may cache identical images
+func (c FileMaskCache) Put(key string, mask []byte) error {
+	path := c.path(key)
+
+	err := c.Storage.MkdirP(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	return c.Storage.Write(path, mask)
+}
+
+// Spread between subdirectories, to keep each one small
+func (c FileMaskCache) path(key string) string {
+	return filepath.Join(c.Directory, key[:2], key+".png")
+}
+
+func (p Processor) cacheMask(planned PlannedImage, params map[string]string, result client.Result) {
+	if p.MaskCache == nil || len(planned.InputHash) == 0 || !strings.Contains(result.ContentType, MimeZip) {
+		return
+	}
+
+	mask, err := composite.ReadMask(bytes.NewReader(result.Data), int64(len(result.Data)))
+	if err == nil {
+		err = p.MaskCache.Put(MaskCacheKey(planned.InputHash, params), mask)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to cache mask: %s\n", err)
+	}
+}
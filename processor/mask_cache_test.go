@@ -0,0 +1,64 @@
+package processor_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/remove-bg/go/processor"
+)
+
+var _ = Describe("FileMaskCache", func() {
+	var (
+		tmpDir  string
+		subject FileMaskCache
+	)
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "mask-cache-spec")
+		Expect(err).ToNot(HaveOccurred())
+
+		tmpDir = dir
+		subject = NewFileMaskCache(filepath.Join(tmpDir, "masks"))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("returns the cached mask", func() {
+		key := MaskCacheKey("abc123", map[string]string{"size": "auto"})
+
+		Expect(subject.Put(key, []byte("mask"))).To(Succeed())
+
+		mask, err := subject.Get(key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mask).To(Equal([]byte("mask")))
+		Expect(filepath.Join(tmpDir, "masks", key[:2], key+".png")).To(BeAnExistingFile())
+	})
+
+	It("errors when the mask isn't cached", func() {
+		_, err := subject.Get(MaskCacheKey("abc123", map[string]string{}))
+
+		Expect(err).To(Equal(ErrMaskNotCached))
+	})
+})
+
+var _ = Describe("MaskCacheKey", func() {
+	params := map[string]string{"size": "full", "type": "car", "format": "zip"}
+
+	It("ignores the transfer format", func() {
+		Expect(MaskCacheKey("abc123", params)).To(Equal(MaskCacheKey("abc123", map[string]string{"size": "full", "type": "car"})))
+	})
+
+	It("changes with the input", func() {
+		Expect(MaskCacheKey("abc123", params)).ToNot(Equal(MaskCacheKey("def456", params)))
+	})
+
+	It("changes with the params", func() {
+		Expect(MaskCacheKey("abc123", params)).ToNot(Equal(MaskCacheKey("abc123", map[string]string{"size": "auto", "type": "car"})))
+		Expect(MaskCacheKey("abc123", params)).ToNot(Equal(MaskCacheKey("abc123", map[string]string{"size": "full", "type": "car", "crop": "true"})))
+	})
+})
@@ -3,14 +3,18 @@ package processor
 import (
 	"fmt"
 	"github.com/mattn/go-colorable"
+	"github.com/remove-bg/go/client"
 	"github.com/sirupsen/logrus"
+	"time"
 )
 
 //go:generate counterfeiter . NotifierInterface
 type NotifierInterface interface {
-	Success(path string, imageNumber int, totalImages int)
+	Success(input string, output string, result client.Result, imageNumber int, totalImages int)
 	Skip(input string, existing string, imageNumber int, totalImages int)
-	Error(err error, path string, imageNumber int, totalImages int)
+	Duplicate(input string, output string, original string, imageNumber int, totalImages int)
+	Error(err error, input string, output string, imageNumber int, totalImages int)
+	Retry(err error, path string, attempt int, delay time.Duration, imageNumber int, totalImages int)
 }
 
 type Notifier struct {
@@ -26,17 +30,30 @@ func NewNotifier() Notifier {
 	}
 }
 
-func (n Notifier) Success(path string, imageNumber int, totalImages int) {
-	n.Logger.WithFields(logrus.Fields{
-		"image": fmt.Sprintf("%d/%d", imageNumber, totalImages),
-		"input": path,
-	}).Info("Processed image")
+func (n Notifier) Success(input string, output string, result client.Result, imageNumber int, totalImages int) {
+	fields := logrus.Fields{
+		"image":   fmt.Sprintf("%d/%d", imageNumber, totalImages),
+		"input":   input,
+		"output":  output,
+		"credits": result.CreditsCharged,
+	}
+
+	if result.Width > 0 && result.Height > 0 {
+		fields["dimensions"] = fmt.Sprintf("%dx%d", result.Width, result.Height)
+	}
+
+	if len(result.DetectedType) > 0 {
+		fields["type"] = result.DetectedType
+	}
+
+	n.Logger.WithFields(fields).Info("Processed image")
 }
 
-func (n Notifier) Error(err error, path string, imageNumber int, totalImages int) {
+func (n Notifier) Error(err error, input string, output string, imageNumber int, totalImages int) {
 	n.Logger.WithFields(logrus.Fields{
-		"image": fmt.Sprintf("%d/%d", imageNumber, totalImages),
-		"input": path,
+		"image":  fmt.Sprintf("%d/%d", imageNumber, totalImages),
+		"input":  input,
+		"output": output,
 	}).Error(err)
 }
 
@@ -47,3 +64,21 @@ func (n Notifier) Skip(input string, existing string, imageNumber int, totalImag
 		"existing": existing,
 	}).Warn("Skipped image")
 }
+
+func (n Notifier) Duplicate(input string, output string, original string, imageNumber int, totalImages int) {
+	n.Logger.WithFields(logrus.Fields{
+		"image":    fmt.Sprintf("%d/%d", imageNumber, totalImages),
+		"input":    input,
+		"output":   output,
+		"original": original,
+	}).Info("Copied duplicate image")
+}
+
+func (n Notifier) Retry(err error, path string, attempt int, delay time.Duration, imageNumber int, totalImages int) {
+	n.Logger.WithFields(logrus.Fields{
+		"image":   fmt.Sprintf("%d/%d", imageNumber, totalImages),
+		"input":   path,
+		"attempt": attempt,
+		"delay":   delay.String(),
+	}).WithError(err).Warn("Retrying image")
+}
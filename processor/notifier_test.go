@@ -5,7 +5,9 @@ import (
 	. "github.com/onsi/gomega"
 
 	"errors"
+	"github.com/remove-bg/go/client"
 	"github.com/sirupsen/logrus/hooks/test"
+	"time"
 
 	. "github.com/remove-bg/go/processor"
 )
@@ -18,7 +20,7 @@ var _ = Describe("Notifier", func() {
 				Logger: logger,
 			}
 
-			subject.Success("input/image.jpg", 1, 2)
+			subject.Success("input/image.jpg", "output/image.png", client.Result{}, 1, 2)
 
 			logged := hook.LastEntry()
 
@@ -26,6 +28,30 @@ var _ = Describe("Notifier", func() {
 			Expect(logged.Message).To(Equal("Processed image"))
 			Expect(logged.Data["image"]).To(Equal("1/2"))
 			Expect(logged.Data["input"]).To(Equal("input/image.jpg"))
+			Expect(logged.Data["output"]).To(Equal("output/image.png"))
+		})
+
+		It("logs the response metadata", func() {
+			logger, hook := test.NewNullLogger()
+			subject := Notifier{
+				Logger: logger,
+			}
+
+			result := client.Result{
+				CreditsCharged: 0.25,
+				Width:          640,
+				Height:         480,
+				DetectedType:   "person",
+			}
+
+			subject.Success("input/image.jpg", "output/image.png", result, 1, 2)
+
+			logged := hook.LastEntry()
+
+			Expect(logged).ToNot(BeNil())
+			Expect(logged.Data["credits"]).To(Equal(0.25))
+			Expect(logged.Data["dimensions"]).To(Equal("640x480"))
+			Expect(logged.Data["type"]).To(Equal("person"))
 		})
 	})
 
@@ -48,6 +74,25 @@ var _ = Describe("Notifier", func() {
 		})
 	})
 
+	Describe("Duplicate", func() {
+		It("logs the image details", func() {
+			logger, hook := test.NewNullLogger()
+			subject := Notifier{
+				Logger: logger,
+			}
+
+			subject.Duplicate("input/copy.jpg", "output/copy.png", "input/image.jpg", 2, 2)
+
+			logged := hook.LastEntry()
+
+			Expect(logged).ToNot(BeNil())
+			Expect(logged.Message).To(Equal("Copied duplicate image"))
+			Expect(logged.Data["image"]).To(Equal("2/2"))
+			Expect(logged.Data["output"]).To(Equal("output/copy.png"))
+			Expect(logged.Data["original"]).To(Equal("input/image.jpg"))
+		})
+	})
+
 	Describe("Error", func() {
 		It("logs the error and image details", func() {
 			logger, hook := test.NewNullLogger()
@@ -56,7 +101,7 @@ var _ = Describe("Notifier", func() {
 			}
 
 			err := errors.New("boom")
-			subject.Error(err, "input/image.jpg", 1, 2)
+			subject.Error(err, "input/image.jpg", "output/image.png", 1, 2)
 
 			logged := hook.LastEntry()
 
@@ -64,6 +109,29 @@ var _ = Describe("Notifier", func() {
 			Expect(logged.Message).To(Equal("boom"))
 			Expect(logged.Data["image"]).To(Equal("1/2"))
 			Expect(logged.Data["input"]).To(Equal("input/image.jpg"))
+			Expect(logged.Data["output"]).To(Equal("output/image.png"))
+		})
+	})
+
+	Describe("Retry", func() {
+		It("logs the error, delay and image details", func() {
+			logger, hook := test.NewNullLogger()
+			subject := Notifier{
+				Logger: logger,
+			}
+
+			err := errors.New("boom")
+			subject.Retry(err, "input/image.jpg", 2, 30*time.Second, 1, 2)
+
+			logged := hook.LastEntry()
+
+			Expect(logged).ToNot(BeNil())
+			Expect(logged.Message).To(Equal("Retrying image"))
+			Expect(logged.Data["error"]).To(Equal(err))
+			Expect(logged.Data["attempt"]).To(Equal(2))
+			Expect(logged.Data["delay"]).To(Equal("30s"))
+			Expect(logged.Data["image"]).To(Equal("1/2"))
+			Expect(logged.Data["input"]).To(Equal("input/image.jpg"))
 		})
 	})
 
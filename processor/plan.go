@@ -0,0 +1,97 @@
+package processor
+
+type PlanAction string
+
+const (
+	PlanProcess   PlanAction = "process"
+	PlanSkip      PlanAction = "skip"
+	PlanOverwrite PlanAction = "overwrite"
+)
+
+type PlannedImage struct {
+	Input     string
+	Output    string
+	InputHash string
+	Action    PlanAction
+}
+
+// Plan describes what processing a batch would do, without calling the API
+type Plan struct {
+	Images           []PlannedImage
+	Process          int
+	Skip             int
+	Overwrite        int
+	EstimatedCredits float64
+}
+
+// Plan expands the input paths and applies the skip-existing logic, without
+// calling the API or creating the output directory
+func (p Processor) Plan(rawInputPaths []string, settings Settings) (Plan, error) {
+	plan := Plan{}
+
+	inputPaths, err := p.Storage.ExpandPaths(rawInputPaths)
+	if err != nil {
+		return plan, err
+	}
+
+	journaled, err := p.loadJournal(settings)
+	if err != nil {
+		return plan, err
+	}
+
+	settings.outputPaths = disambiguateURLOutputs(inputPaths, settings)
+
+	for _, inputPath := range inputPaths {
+		image := p.planImage(inputPath, settings, journaled)
+		plan.Images = append(plan.Images, image)
+
+		switch image.Action {
+		case PlanProcess:
+			plan.Process++
+		case PlanSkip:
+			plan.Skip++
+		case PlanOverwrite:
+			plan.Overwrite++
+		}
+	}
+
+	plan.EstimatedCredits = float64(plan.Process+plan.Overwrite) * EstimateCredits(settings.ImageSettings.Size)
+
+	return plan, nil
+}
+
+// When resuming, the journal rather than the output file decides whether an
+// image was already processed, so partially written outputs are redone
+func (p Processor) planImage(inputPath string, settings Settings, journaled map[string]JournalEntry) PlannedImage {
+	image := PlannedImage{
+		Input:  inputPath,
+		Output: settings.outputPath(inputPath),
+		Action: PlanProcess,
+	}
+
+	if (p.Journal != nil || p.MaskCache != nil || settings.Dedupe) && !IsURL(inputPath) {
+		image.InputHash, _ = p.Storage.Checksum(inputPath)
+	}
+
+	if settings.Resume && p.Journal != nil {
+		entry, ok := journaled[inputPath]
+		if ok && entry.completed(image.InputHash) {
+			image.Output = entry.Output
+			image.Action = PlanSkip
+		} else if p.Storage.FileExists(image.Output) {
+			image.Action = PlanOverwrite
+		}
+
+		return image
+	}
+
+	if p.Storage.FileExists(image.Output) {
+		if settings.ReprocessExisting {
+			image.Action = PlanOverwrite
+		} else {
+			image.Action = PlanSkip
+		}
+	}
+
+	return image
+}
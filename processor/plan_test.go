@@ -0,0 +1,83 @@
+package processor_test
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/client/clientfakes"
+	"github.com/remove-bg/go/processor"
+	"github.com/remove-bg/go/storage/storagefakes"
+)
+
+var _ = Describe("Plan", func() {
+	var (
+		fakeClient   *clientfakes.FakeClientInterface
+		fakeStorage  *storagefakes.FakeStorageInterface
+		subject      processor.Processor
+		testSettings processor.Settings
+	)
+
+	BeforeEach(func() {
+		fakeClient = &clientfakes.FakeClientInterface{}
+		fakeStorage = &storagefakes.FakeStorageInterface{}
+		fakeStorage.ExpandPathsReturns([]string{"dir/image1.jpg", "dir/image2.jpg", "dir/image3.jpg"}, nil)
+		fakeStorage.FileExistsStub = func(path string) bool {
+			return path == "out/image2.png"
+		}
+
+		subject = processor.Processor{
+			APIKey:  "api-key",
+			Client:  fakeClient,
+			Storage: fakeStorage,
+		}
+
+		testSettings = processor.Settings{
+			OutputDirectory: "out",
+			ImageSettings: processor.ImageSettings{
+				Size: "preview",
+			},
+		}
+	})
+
+	It("plans each expanded input", func() {
+		plan, err := subject.Plan([]string{"dir/*.jpg"}, testSettings)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.Images).To(Equal([]processor.PlannedImage{
+			{Input: "dir/image1.jpg", Output: "out/image1.png", Action: processor.PlanProcess},
+			{Input: "dir/image2.jpg", Output: "out/image2.png", Action: processor.PlanSkip},
+			{Input: "dir/image3.jpg", Output: "out/image3.png", Action: processor.PlanProcess},
+		}))
+		Expect(plan.Process).To(Equal(2))
+		Expect(plan.Skip).To(Equal(1))
+		Expect(plan.Overwrite).To(Equal(0))
+		Expect(plan.EstimatedCredits).To(Equal(0.5))
+	})
+
+	It("plans to overwrite existing outputs when reprocessing", func() {
+		testSettings.ReprocessExisting = true
+
+		plan, err := subject.Plan([]string{"dir/*.jpg"}, testSettings)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.Images[1].Action).To(Equal(processor.PlanOverwrite))
+		Expect(plan.Overwrite).To(Equal(1))
+		Expect(plan.EstimatedCredits).To(Equal(0.75))
+	})
+
+	It("doesn't call the API or create the output directory", func() {
+		subject.Plan([]string{"dir/*.jpg"}, testSettings)
+
+		Expect(fakeStorage.MkdirPCallCount()).To(Equal(0))
+		Expect(fakeStorage.WriteCallCount()).To(Equal(0))
+		Expect(fakeClient.Invocations()).To(BeEmpty())
+	})
+
+	It("returns any error expanding the paths", func() {
+		fakeStorage.ExpandPathsReturns(nil, errors.New("bad pattern"))
+
+		_, err := subject.Plan([]string{"dir/[.jpg"}, testSettings)
+
+		Expect(err).To(MatchError("bad pattern"))
+	})
+})
@@ -1,26 +1,32 @@
 package processor
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/remove-bg/go/client"
 	"github.com/remove-bg/go/composite"
 	"github.com/remove-bg/go/storage"
-	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
-	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 )
 
 type Processor struct {
-	APIKey     string
-	Client     client.ClientInterface
-	Storage    storage.StorageInterface
-	Prompt     PromptInterface
-	Notifier   NotifierInterface
-	Compositor composite.CompositorInterface
+	APIKey      string
+	Client      client.ClientInterface
+	Storage     storage.StorageInterface
+	Prompt      PromptInterface
+	Notifier    NotifierInterface
+	Compositor  composite.CompositorInterface
+	Journal     JournalInterface   // Optional
+	MaskCache   MaskCacheInterface // Optional
+	Keys        *KeyPool           // Optional, used instead of the APIKey
+	DedupeIndex *DedupeIndex       // Optional, shared between calls to Process
 }
 
 type Settings struct {
@@ -28,7 +34,22 @@ type Settings struct {
 	ReprocessExisting          bool
 	SkipPngFormatOptimization  bool
 	LargeBatchConfirmThreshold int
+	Concurrency                int
+	Resume                     bool
+	Dedupe                     bool // Process byte-identical images once, copying the output
+	MaxCredits                 float64
+	RateLimit                  RateLimitSettings
+	Retry                      RetryPolicy
 	ImageSettings              ImageSettings
+	CompositeOptions           *composite.Options // Optional, built from the ImageSettings when nil
+	outputPaths                map[string]string  // Of URLs which would collide, see disambiguateURLOutputs
+}
+
+// RateLimitSettings control how long to back off when the API rate limit is
+// exceeded, before giving up on the batch
+type RateLimitSettings struct {
+	MaxWait    time.Duration
+	MaxRetries int
 }
 
 type ImageSettings struct {
@@ -37,6 +58,7 @@ type ImageSettings struct {
 	Channels        string
 	BgColor         string
 	BgImageFile     string
+	BgMode          string // How the background image is sized, see composite.BackgroundMode
 	OutputFormat    string
 	ExtraApiOptions string
 	transferFormat  string
@@ -56,7 +78,9 @@ func NewProcessor(apiKey string, version string) Processor {
 	}
 }
 
-func (p Processor) Process(rawInputPaths []string, settings Settings) {
+// Process removes the background from every input. Cancelling the context
+// stops new images being started and abandons any in-flight API requests.
+func (p Processor) Process(ctx context.Context, rawInputPaths []string, settings Settings) Summary {
 	err := p.Storage.MkdirP(settings.OutputDirectory)
 	if err != nil {
 		log.Fatal(err)
@@ -67,37 +91,321 @@ func (p Processor) Process(rawInputPaths []string, settings Settings) {
 		log.Fatal(err)
 	}
 
-	confirmation := p.confirmLargeBatch(inputPaths, settings)
+	journaled, err := p.loadJournal(settings)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	settings.outputPaths = disambiguateURLOutputs(inputPaths, settings)
+
+	compositeOptions, err := settings.compositeOptions()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	startedAt := time.Now()
+	totalImages := len(inputPaths)
+	summary := Summary{Total: totalImages}
+
+	confirmation := p.confirmLargeBatch(ctx, inputPaths, settings)
 	if !confirmation {
-		return
+		summary.Cancelled = true
+		summary.Duration = time.Since(startedAt)
+		return summary
 	}
 
 	settings.setTransferFormat()
 
-	totalImages := len(inputPaths)
+	// Masks are only in ZIP responses, so every output is composited here
+	if p.MaskCache != nil {
+		settings.ImageSettings.transferFormat = FormatZip
+	}
+
+	// Time spent at the prompt shouldn't count towards the throughput
+	if n, ok := p.Notifier.(interface{ Start() }); ok {
+		n.Start()
+	}
 
+	jobs := make(chan job)
+	b := &batch{
+		settings:    settings,
+		totalImages: totalImages,
+		control:     newBatchControl(ctx),
+		budget:      &creditBudget{max: settings.MaxCredits, estimate: EstimateCredits(settings.ImageSettings.Size)},
+		journaled:   journaled,
+		options:     compositeOptions,
+		fingerprint: settings.ImageSettings.fingerprint(),
+	}
+	b.dedupe = p.newDedupe(settings, journaled, b)
+
+	var wg sync.WaitGroup
+	var summaryMutex sync.Mutex
+
+	for w := 0; w < settings.workerCount(totalImages); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := range jobs {
+				// A job may have been handed over just before another worker halted
+				if b.control.halted() {
+					continue
+				}
+
+				outcome := p.processImage(ctx, j, b)
+
+				summaryMutex.Lock()
+				summary.record(j.inputPath, outcome)
+				summaryMutex.Unlock()
+			}
+		}()
+	}
+
+dispatch:
 	for index, inputPath := range inputPaths {
-		outputPath := DetermineOutputPath(inputPath, settings)
-		skipImage := p.Storage.FileExists(outputPath) && !settings.ReprocessExisting
+		select {
+		case <-b.control.done:
+			break dispatch
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- job{inputPath: inputPath, imageNumber: index + 1}:
+		}
+	}
 
-		if skipImage {
-			p.Notifier.Skip(inputPath, outputPath, index+1, totalImages)
-			continue
+	close(jobs)
+	wg.Wait()
+
+	summary.Cancelled = ctx.Err() != nil
+	summary.BudgetExceeded = b.control.budgetExceeded
+	summary.Duration = time.Since(startedAt)
+	return summary
+}
+
+// batch is the state shared by every worker while processing
+type batch struct {
+	settings    Settings
+	totalImages int
+	control     *batchControl
+	budget      *creditBudget
+	journaled   map[string]JournalEntry
+	options     composite.Options
+	fingerprint string
+	dedupe      *dedupe // Optional
+}
+
+// writtenPath is where an output is saved, as ZIPs are composited
+func (b *batch) writtenPath(output string) string {
+	if b.settings.ImageSettings.TransferFormat() == FormatZip {
+		return compositeOutputPath(output, b.options)
+	}
+
+	return output
+}
+
+type job struct {
+	inputPath   string
+	imageNumber int
+}
+
+func (p Processor) processImage(ctx context.Context, j job, b *batch) imageOutcome {
+	settings := b.settings
+	planned := p.planImage(j.inputPath, settings, b.journaled)
+	outputPath := planned.Output
+
+	if planned.Action == PlanSkip {
+		p.Notifier.Skip(j.inputPath, outputPath, j.imageNumber, b.totalImages)
+		return imageOutcome{result: imageSkipped, output: outputPath}
+	}
+
+	o, first := b.dedupe.claim(planned, b)
+	if !first {
+		return p.copyDuplicate(ctx, j, b, planned, o)
+	}
+
+	outcome := p.removeBackground(ctx, j, b, planned)
+	o.finish(outcome)
+
+	return outcome
+}
+
+func (p Processor) removeBackground(ctx context.Context, j job, b *batch, planned PlannedImage) imageOutcome {
+	settings := b.settings
+	outputPath := planned.Output
+
+	reserved, ok := b.budget.reserve()
+	if !ok {
+		b.control.exceedBudget()
+		return imageOutcome{result: imageNotStarted, output: outputPath}
+	}
+
+	var result client.Result
+	var err error
+	rateLimitedRetries, transientRetries := 0, 0
+
+	for attempt := 1; ; attempt++ {
+		b.control.waitForResume()
+		if b.control.halted() {
+			b.budget.settle(reserved, 0, false)
+			return imageOutcome{result: imageNotStarted, output: outputPath}
 		}
 
-		err := p.processFile(inputPath, outputPath, settings.ImageSettings)
+		apiKey := p.apiKey()
+		result, err = p.processFile(ctx, apiKey, j.inputPath, outputPath, settings.ImageSettings, b.options)
 
-		if err == nil {
-			p.Notifier.Success(inputPath, index+1, totalImages)
-		} else {
-			p.Notifier.Error(err, inputPath, index+1, totalImages)
+		if err == nil || b.control.halted() {
+			break
+		}
 
-			clientErr, ok := err.(*client.RequestError)
-			if ok && clientErr.RateLimitExceeded() {
-				return // Halt processing loop
-			}
+		if p.Keys != nil && p.Keys.Rotate(apiKey, err) {
+			p.Notifier.Retry(err, j.inputPath, attempt, 0, j.imageNumber, b.totalImages)
+			continue
 		}
+
+		if delay, ok := settings.RateLimit.retryDelay(err, rateLimitedRetries+1); ok {
+			rateLimitedRetries++
+			p.Notifier.Retry(err, j.inputPath, attempt, delay, j.imageNumber, b.totalImages)
+			b.control.pause(delay) // Every worker backs off, not just this one
+			continue
+		}
+
+		if delay, ok := settings.Retry.retryDelay(err, transientRetries+1); ok {
+			transientRetries++
+			p.Notifier.Retry(err, j.inputPath, attempt, delay, j.imageNumber, b.totalImages)
+			b.control.sleep(delay)
+			continue
+		}
+
+		break
+	}
+
+	b.budget.settle(reserved, result.CreditsCharged, err == nil)
+	p.recordJournal(planned, b.fingerprint, result, err)
+
+	outcome := imageOutcome{credits: result.CreditsCharged, output: outputPath}
+
+	if err == nil {
+		p.cacheMask(planned, imageSettingsToParams(settings.ImageSettings), result)
+		p.Notifier.Success(j.inputPath, outputPath, result, j.imageNumber, b.totalImages)
+		outcome.result = imageProcessed
+		return outcome
+	}
+
+	p.Notifier.Error(err, j.inputPath, outputPath, j.imageNumber, b.totalImages)
+
+	// Every other image would fail the same way, so stop every worker
+	clientErr, ok := err.(*client.RequestError)
+	if ok && (clientErr.RateLimitExceeded() || clientErr.AuthenticationFailed()) {
+		b.control.halt()
+	}
+
+	outcome.result = imageFailed
+	outcome.err = err
+	return outcome
+}
+
+// Used when the API doesn't say how long to wait
+const defaultRateLimitWait = 10 * time.Second
+
+func (r RateLimitSettings) retryDelay(err error, attempt int) (time.Duration, bool) {
+	clientErr, ok := err.(*client.RequestError)
+	if !ok || !clientErr.RateLimitExceeded() || attempt > r.MaxRetries {
+		return 0, false
+	}
+
+	delay := clientErr.RetryDelay(time.Now())
+	if delay <= 0 {
+		delay = defaultRateLimitWait
+	}
+
+	if delay > r.MaxWait {
+		return 0, false
+	}
+
+	return delay, true
+}
+
+// batchControl is shared between workers so any one of them can pause or
+// stop the batch
+type batchControl struct {
+	ctx            context.Context
+	done           chan struct{}
+	once           sync.Once
+	mutex          sync.Mutex
+	pauseUntil     time.Time
+	budgetExceeded bool
+}
+
+func newBatchControl(ctx context.Context) *batchControl {
+	return &batchControl{ctx: ctx, done: make(chan struct{})}
+}
+
+func (b *batchControl) halt() {
+	b.once.Do(func() { close(b.done) })
+}
+
+func (b *batchControl) exceedBudget() {
+	b.mutex.Lock()
+	b.budgetExceeded = true
+	b.mutex.Unlock()
+
+	b.halt()
+}
+
+func (b *batchControl) halted() bool {
+	select {
+	case <-b.done:
+		return true
+	case <-b.ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *batchControl) pause(delay time.Duration) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	until := time.Now().Add(delay)
+	if until.After(b.pauseUntil) {
+		b.pauseUntil = until
+	}
+}
+
+func (b *batchControl) waitForResume() {
+	b.mutex.Lock()
+	delay := time.Until(b.pauseUntil)
+	b.mutex.Unlock()
+
+	b.sleep(delay)
+}
+
+// sleep waits for the delay, or until the batch is halted
+func (b *batchControl) sleep(delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-b.done:
+	case <-b.ctx.Done():
+	}
+}
+
+func (s Settings) workerCount(totalImages int) int {
+	if s.Concurrency < 1 {
+		return 1
+	}
+
+	if totalImages > 0 && s.Concurrency > totalImages {
+		return totalImages
 	}
+
+	return s.Concurrency
 }
 
 const FormatPng = "png"
@@ -105,8 +413,10 @@ const FormatZip = "zip"
 const MimeZip = "application/zip"
 
 func (s *Settings) setTransferFormat() {
-	// Save network bandwidth by requesting ZIP format (output will still be a PNG)
-	if !s.SkipPngFormatOptimization && s.ImageSettings.OutputFormat == FormatPng {
+	// Save network bandwidth by requesting ZIP format (output will still be a
+	// PNG). Backgrounds are applied locally, which needs the ZIP's alpha mask.
+	optimizePng := !s.SkipPngFormatOptimization && s.ImageSettings.OutputFormat == FormatPng
+	if optimizePng || s.ImageSettings.localBackground() {
 		s.ImageSettings.transferFormat = FormatZip
 	} else {
 		s.ImageSettings.transferFormat = s.ImageSettings.OutputFormat
@@ -117,20 +427,42 @@ func (is *ImageSettings) TransferFormat() string {
 	return is.transferFormat
 }
 
-func (p Processor) processFile(inputPath string, outputPath string, imageSettings ImageSettings) error {
+// APIParams are the request parameters for the image settings
+func (s Settings) APIParams() map[string]string {
+	s.setTransferFormat()
+	return imageSettingsToParams(s.ImageSettings)
+}
+
+func (p Processor) processFile(ctx context.Context, apiKey string, inputPath string, outputPath string, imageSettings ImageSettings, options composite.Options) (client.Result, error) {
 	params := imageSettingsToParams(imageSettings)
-	processedBytes, contentType, err := p.Client.RemoveFromFile(inputPath, p.APIKey, params)
+	result, err := p.remove(ctx, apiKey, inputPath, params)
 	if err != nil {
-		return err
+		return result, err
 	}
 
-	if strings.Contains(contentType, MimeZip) {
-		return p.processCompositeFile(outputPath, processedBytes)
+	if strings.Contains(result.ContentType, MimeZip) {
+		return result, p.processCompositeFile(ctx, outputPath, result.Data, options)
 	} else {
-		return p.Storage.Write(outputPath, processedBytes)
+		return result, p.Storage.Write(outputPath, result.Data)
 	}
 }
 
+func (p Processor) remove(ctx context.Context, apiKey string, inputPath string, params map[string]string) (client.Result, error) {
+	if IsURL(inputPath) {
+		return p.Client.RemoveFromURL(ctx, inputPath, apiKey, params)
+	}
+
+	return p.Client.RemoveFromFile(ctx, inputPath, apiKey, params)
+}
+
+func (p Processor) apiKey() string {
+	if p.Keys != nil {
+		return p.Keys.Current()
+	}
+
+	return p.APIKey
+}
+
 func imageSettingsToParams(imageSettings ImageSettings) map[string]string {
 	// TODO: Tidyup with reflection / struct tags?
 	params := map[string]string{}
@@ -147,14 +479,6 @@ func imageSettingsToParams(imageSettings ImageSettings) map[string]string {
 		params["channels"] = imageSettings.Channels
 	}
 
-	if len(imageSettings.BgColor) > 0 {
-		params["bg_color"] = imageSettings.BgColor
-	}
-
-	if len(imageSettings.BgImageFile) > 0 {
-		params["bg_image_file"] = imageSettings.BgImageFile
-	}
-
 	if len(imageSettings.TransferFormat()) > 0 {
 		params["format"] = imageSettings.TransferFormat()
 	}
@@ -174,7 +498,7 @@ func imageSettingsToParams(imageSettings ImageSettings) map[string]string {
 	return params
 }
 
-func (p Processor) confirmLargeBatch(inputPaths []string, settings Settings) bool {
+func (p Processor) confirmLargeBatch(ctx context.Context, inputPaths []string, settings Settings) bool {
 	batchSize := len(inputPaths)
 	skipConfirm := settings.LargeBatchConfirmThreshold < 0
 
@@ -182,24 +506,36 @@ func (p Processor) confirmLargeBatch(inputPaths []string, settings Settings) boo
 		return true
 	}
 
-	return p.Prompt.ConfirmLargeBatch(batchSize)
-}
+	estimate := CreditEstimate{
+		Credits: float64(batchSize) * EstimateCredits(settings.ImageSettings.Size),
+	}
 
-func (p Processor) processCompositeFile(outputPath string, processedBytes []byte) error {
-	file, err := ioutil.TempFile("", "removebg.*.zip")
-	if err != nil {
-		return err
+	account, err := p.Client.Account(ctx, p.apiKey())
+	if err == nil {
+		estimate.Balance = account.TotalCredits
+		estimate.BalanceKnown = true
 	}
 
-	defer os.Remove(file.Name())
+	return p.Prompt.ConfirmLargeBatch(batchSize, estimate)
+}
+
+func (p Processor) processCompositeFile(ctx context.Context, outputPath string, processedBytes []byte, options composite.Options) error {
+	composited := new(bytes.Buffer)
 
-	_, err = file.Write(processedBytes)
+	err := p.Compositor.Composite(ctx, bytes.NewReader(processedBytes), int64(len(processedBytes)), composited, options)
 	if err != nil {
 		return err
 	}
 
-	// Convert output/foo.zip -> output/foo.png
-	pngOutputPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".png"
+	return p.Storage.Write(compositeOutputPath(outputPath, options), composited.Bytes())
+}
+
+// JPEGs are only composited onto a background, everything else is a PNG,
+// e.g. output/foo.zip -> output/foo.png
+func compositeOutputPath(outputPath string, options composite.Options) string {
+	if options.Format == composite.FormatJpg {
+		return outputPath
+	}
 
-	return p.Compositor.Process(file.Name(), pngOutputPath)
+	return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".png"
 }
@@ -1,15 +1,23 @@
 package processor_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/remove-bg/go/client"
 	"github.com/remove-bg/go/client/clientfakes"
+	"github.com/remove-bg/go/composite"
 	"github.com/remove-bg/go/composite/compositefakes"
 	"github.com/remove-bg/go/processor"
 	"github.com/remove-bg/go/processor/processorfakes"
 	"github.com/remove-bg/go/storage/storagefakes"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"time"
 )
 
 const mimePng = "image/png"
@@ -58,33 +66,33 @@ var _ = Describe("Processor", func() {
 			return []string{"dir/image1.jpg"}, nil
 		}
 
-		subject.Process([]string{"dir/*.jpg"}, testSettings)
+		subject.Process(context.Background(), []string{"dir/*.jpg"}, testSettings)
 
 		Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
 		Expect(fakeStorage.ExpandPathsCallCount()).To(Equal(1))
 
-		clientArg1, _, _ := fakeClient.RemoveFromFileArgsForCall(0)
+		_, clientArg1, _, _ := fakeClient.RemoveFromFileArgsForCall(0)
 		Expect(clientArg1).To(Equal("dir/image1.jpg"))
 	})
 
 	It("create the output directory", func() {
-		subject.Process([]string{"dir/*.jpg"}, testSettings)
+		subject.Process(context.Background(), []string{"dir/*.jpg"}, testSettings)
 
 		Expect(fakeStorage.MkdirPCallCount()).To(Equal(1))
 		Expect(fakeStorage.MkdirPArgsForCall(0)).To(Equal(testSettings.OutputDirectory))
 	})
 
 	It("coordinates the HTTP request and writing the result", func() {
-		fakeClient.RemoveFromFileReturnsOnCall(0, []byte("Processed1"), mimePng, nil)
-		fakeClient.RemoveFromFileReturnsOnCall(1, []byte("Processed2"), mimePng, nil)
+		fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{Data: []byte("Processed1"), ContentType: mimePng}, nil)
+		fakeClient.RemoveFromFileReturnsOnCall(1, client.Result{Data: []byte("Processed2"), ContentType: mimePng}, nil)
 
 		inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg"}
 
-		subject.Process(inputPaths, testSettings)
+		subject.Process(context.Background(), inputPaths, testSettings)
 
 		Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(2))
 
-		clientArg1, clientArg2, params := fakeClient.RemoveFromFileArgsForCall(0)
+		_, clientArg1, clientArg2, params := fakeClient.RemoveFromFileArgsForCall(0)
 		Expect(clientArg1).To(Equal("dir/image1.jpg"))
 		Expect(clientArg2).To(Equal("api-key"))
 		Expect(len(params)).To(Equal(0))
@@ -96,31 +104,82 @@ var _ = Describe("Processor", func() {
 		Expect(writerArg2).To(Equal([]byte("Processed1")))
 	})
 
+	It("notifies the API response metadata", func() {
+		result := client.Result{
+			Data:           []byte("Processed1"),
+			ContentType:    mimePng,
+			CreditsCharged: 1,
+			DetectedType:   "product",
+		}
+		fakeClient.RemoveFromFileReturns(result, nil)
+
+		subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
+
+		Expect(fakeNotifier.SuccessCallCount()).To(Equal(1))
+		notifiedPath, notifiedOutput, notifiedResult, _, _ := fakeNotifier.SuccessArgsForCall(0)
+		Expect(notifiedPath).To(Equal("dir/image1.jpg"))
+		Expect(notifiedOutput).To(Equal("output-dir/image1.png"))
+		Expect(notifiedResult).To(Equal(result))
+	})
+
+	It("passes URLs to the API instead of uploading a file", func() {
+		fakeClient.RemoveFromURLReturns(client.Result{Data: []byte("Processed1"), ContentType: mimePng}, nil)
+
+		subject.Process(context.Background(), []string{"https://cdn.example/image1.jpg"}, testSettings)
+
+		Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(0))
+		Expect(fakeClient.RemoveFromURLCallCount()).To(Equal(1))
+
+		_, imageURL, apiKey, _ := fakeClient.RemoveFromURLArgsForCall(0)
+		Expect(imageURL).To(Equal("https://cdn.example/image1.jpg"))
+		Expect(apiKey).To(Equal("api-key"))
+
+		writerArg1, writerArg2 := fakeStorage.WriteArgsForCall(0)
+		Expect(writerArg1).To(Equal("output-dir/image1.png"))
+		Expect(writerArg2).To(Equal([]byte("Processed1")))
+	})
+
+	It("adds a hash of the URL to outputs which would otherwise collide", func() {
+		fakeClient.RemoveFromURLReturns(client.Result{Data: []byte("Processed"), ContentType: mimePng}, nil)
+		inputPaths := []string{"https://cdn.example/p/1/main.jpg", "https://cdn.example/p/2/main.jpg", "https://cdn.example/p/3/other.jpg"}
+
+		summary := subject.Process(context.Background(), inputPaths, testSettings)
+
+		Expect(summary.Processed).To(Equal(3))
+		outputs := []string{}
+		for i := 0; i < fakeStorage.WriteCallCount(); i++ {
+			output, _ := fakeStorage.WriteArgsForCall(i)
+			outputs = append(outputs, output)
+		}
+
+		Expect(outputs).To(ConsistOf("output-dir/main-6632641e.png", "output-dir/main-54814246.png", "output-dir/other.png"))
+	})
+
 	Context("zip format requested", func() {
 		It("delegates to the compositor", func() {
-			fakeCompositor.ProcessReturns(nil)
-			fakeClient.RemoveFromFileReturnsOnCall(0, []byte("Zip1"), processor.MimeZip, nil)
-			fakeClient.RemoveFromFileReturnsOnCall(1, []byte("Zip2"), processor.MimeZip, nil)
+			fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{Data: []byte("Zip1"), ContentType: processor.MimeZip}, nil)
+			fakeClient.RemoveFromFileReturnsOnCall(1, client.Result{Data: []byte("Zip2"), ContentType: processor.MimeZip}, nil)
 
 			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg"}
 			testSettings.OutputDirectory = "out-dir"
 			testSettings.ImageSettings.OutputFormat = processor.FormatZip
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
+
+			Expect(fakeCompositor.CompositeCallCount()).To(Equal(2))
 
-			Expect(fakeCompositor.ProcessCallCount()).To(Equal(2))
+			_, zipData, size, _, _ := fakeCompositor.CompositeArgsForCall(0)
+			Expect(ioutil.ReadAll(io.NewSectionReader(zipData, 0, size))).To(Equal([]byte("Zip1")))
 
-			zipFileName, outputPath := fakeCompositor.ProcessArgsForCall(0)
-			Expect(zipFileName).To(ContainSubstring(".zip"))
+			outputPath, _ := fakeStorage.WriteArgsForCall(0)
 			Expect(outputPath).To(Equal("out-dir/image1.png"))
 		})
 	})
 
 	Context("png format requested", func() {
 		BeforeEach(func() {
-			fakeCompositor.ProcessReturns(nil)
-			fakeClient.RemoveFromFileReturnsOnCall(0, []byte("Zip1"), processor.MimeZip, nil)
-			fakeClient.RemoveFromFileReturnsOnCall(1, []byte("Zip2"), processor.MimeZip, nil)
+			fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{Data: []byte("Zip1"), ContentType: processor.MimeZip}, nil)
+			fakeClient.RemoveFromFileReturnsOnCall(1, client.Result{Data: []byte("Zip2"), ContentType: processor.MimeZip}, nil)
 		})
 
 		It("upgrades the format to zip behind the scenes", func() {
@@ -128,10 +187,10 @@ var _ = Describe("Processor", func() {
 			testSettings.OutputDirectory = "out-dir"
 			testSettings.ImageSettings.OutputFormat = processor.FormatPng
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(2))
-			_, _, params := fakeClient.RemoveFromFileArgsForCall(0)
+			_, _, _, params := fakeClient.RemoveFromFileArgsForCall(0)
 			Expect(params["format"]).To(Equal(processor.FormatZip))
 		})
 
@@ -140,25 +199,51 @@ var _ = Describe("Processor", func() {
 			testSettings.OutputDirectory = "out-dir"
 			testSettings.ImageSettings.OutputFormat = processor.FormatPng
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
-			Expect(fakeCompositor.ProcessCallCount()).To(Equal(2))
+			Expect(fakeCompositor.CompositeCallCount()).To(Equal(2))
 
-			zipFileName, outputPath := fakeCompositor.ProcessArgsForCall(0)
-			Expect(zipFileName).To(ContainSubstring(".zip"))
+			_, zipData, size, _, _ := fakeCompositor.CompositeArgsForCall(0)
+			Expect(ioutil.ReadAll(io.NewSectionReader(zipData, 0, size))).To(Equal([]byte("Zip1")))
+
+			outputPath, _ := fakeStorage.WriteArgsForCall(0)
 			Expect(outputPath).To(Equal("out-dir/image1.png"))
 		})
 
+		It("writes the composited PNG without a temporary file", func() {
+			fakeCompositor.CompositeStub = func(ctx context.Context, zipData io.ReaderAt, size int64, output io.Writer, options composite.Options) error {
+				_, err := io.WriteString(output, "Composited")
+				return err
+			}
+			testSettings.ImageSettings.OutputFormat = processor.FormatPng
+
+			subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
+
+			Expect(fakeStorage.WriteCallCount()).To(Equal(1))
+			_, data := fakeStorage.WriteArgsForCall(0)
+			Expect(data).To(Equal([]byte("Composited")))
+		})
+
+		It("doesn't write the output when compositing fails", func() {
+			fakeCompositor.CompositeReturns(errors.New("Unable to find image in ZIP: alpha.png"))
+			testSettings.ImageSettings.OutputFormat = processor.FormatPng
+
+			subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
+
+			Expect(fakeStorage.WriteCallCount()).To(Equal(0))
+			Expect(fakeNotifier.ErrorCallCount()).To(Equal(1))
+		})
+
 		It("allows the optimization to be skipped", func() {
 			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg"}
 			testSettings.OutputDirectory = "out-dir"
 			testSettings.ImageSettings.OutputFormat = processor.FormatPng
 			testSettings.SkipPngFormatOptimization = true
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(2))
-			_, _, params := fakeClient.RemoveFromFileArgsForCall(0)
+			_, _, _, params := fakeClient.RemoveFromFileArgsForCall(0)
 			Expect(params["format"]).To(Equal(processor.FormatPng))
 		})
 
@@ -169,7 +254,7 @@ var _ = Describe("Processor", func() {
 			inputPaths := []string{"dir/image1.jpg"}
 			fakeStorage.FileExistsReturnsOnCall(0, true)
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakeNotifier.SkipCallCount()).To(Equal(1))
 			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(0))
@@ -179,33 +264,29 @@ var _ = Describe("Processor", func() {
 
 	Describe("image options", func() {
 		It("passes non-empty image options to the client", func() {
-			fakeClient.RemoveFromFileReturnsOnCall(0, []byte("Processed1"), mimePng, nil)
+			fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{Data: []byte("Processed1"), ContentType: mimePng}, nil)
 			inputPaths := []string{"dir/image1.jpg"}
 
 			testSettings.ImageSettings = processor.ImageSettings{
 				Size:         "size-value",
 				Type:         "type-value",
 				Channels:     "channels-value",
-				BgColor:      "bg-color-value",
-				BgImageFile:  "bg-image-file-value",
 				OutputFormat: "format-value",
 			}
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
-			_, _, params := fakeClient.RemoveFromFileArgsForCall(0)
+			_, _, _, params := fakeClient.RemoveFromFileArgsForCall(0)
 
 			Expect(params["size"]).To(Equal("size-value"))
 			Expect(params["type"]).To(Equal("type-value"))
 			Expect(params["channels"]).To(Equal("channels-value"))
-			Expect(params["bg_color"]).To(Equal("bg-color-value"))
-			Expect(params["bg_image_file"]).To(Equal("bg-image-file-value"))
 			Expect(params["format"]).To(Equal("format-value"))
 		})
 
 		It("parses any extra API options into params", func() {
-			fakeClient.RemoveFromFileReturnsOnCall(0, []byte("Processed1"), mimePng, nil)
+			fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{Data: []byte("Processed1"), ContentType: mimePng}, nil)
 			inputPaths := []string{"dir/image1.jpg"}
 
 			testSettings.ImageSettings = processor.ImageSettings{
@@ -213,24 +294,108 @@ var _ = Describe("Processor", func() {
 				ExtraApiOptions: "option1=val1&option2=val2",
 			}
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
-			_, _, params := fakeClient.RemoveFromFileArgsForCall(0)
+			_, _, _, params := fakeClient.RemoveFromFileArgsForCall(0)
 
 			Expect(params["size"]).To(Equal("size-value"))
 			Expect(params["option1"]).To(Equal("val1"))
 			Expect(params["option2"]).To(Equal("val2"))
 		})
+
+		It("exposes the params with the transfer format", func() {
+			testSettings.ImageSettings = processor.ImageSettings{
+				Size:         "preview",
+				OutputFormat: "png",
+			}
+
+			Expect(testSettings.APIParams()).To(Equal(map[string]string{
+				"size":   "preview",
+				"format": "zip",
+			}))
+		})
+	})
+
+	Describe("local background", func() {
+		BeforeEach(func() {
+			fakeClient.RemoveFromFileReturns(client.Result{Data: []byte("Zip1"), ContentType: processor.MimeZip}, nil)
+		})
+
+		It("requests a ZIP without sending the background to the API", func() {
+			testSettings.ImageSettings = processor.ImageSettings{
+				OutputFormat: processor.FormatPng,
+				BgColor:      "81d4fa",
+				BgImageFile:  "../fixtures/background.jpg",
+			}
+			testSettings.SkipPngFormatOptimization = true
+
+			subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
+
+			_, _, _, params := fakeClient.RemoveFromFileArgsForCall(0)
+			Expect(params).To(Equal(map[string]string{"format": processor.FormatZip}))
+		})
+
+		It("composites onto the background", func() {
+			testSettings.ImageSettings = processor.ImageSettings{
+				OutputFormat: processor.FormatPng,
+				BgColor:      "81d4fa",
+				BgImageFile:  "../fixtures/background.jpg",
+				BgMode:       "tile",
+			}
+
+			subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
+
+			_, _, _, _, options := fakeCompositor.CompositeArgsForCall(0)
+			Expect(options.Format).To(Equal(composite.FormatPng))
+			Expect(options.Background.Color).To(Equal(&color.NRGBA{R: 0x81, G: 0xd4, B: 0xfa, A: 0xff}))
+			Expect(options.Background.Image).ToNot(BeNil())
+			Expect(options.Background.Mode).To(Equal(composite.BackgroundTile))
+		})
+
+		It("uses composite options built in advance", func() {
+			testSettings.ImageSettings = processor.ImageSettings{
+				OutputFormat: processor.FormatPng,
+				BgImageFile:  "../fixtures/missing.jpg",
+			}
+			testSettings.CompositeOptions = &composite.Options{
+				Format:     composite.FormatPng,
+				Background: composite.Background{Color: &color.NRGBA{A: 0xff}},
+			}
+
+			subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
+
+			_, _, _, _, options := fakeCompositor.CompositeArgsForCall(0)
+			Expect(options).To(Equal(*testSettings.CompositeOptions))
+		})
+
+		It("writes JPEGs when requested", func() {
+			testSettings.ImageSettings = processor.ImageSettings{
+				OutputFormat: "jpg",
+				BgColor:      "white",
+			}
+
+			subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
+
+			_, _, _, params := fakeClient.RemoveFromFileArgsForCall(0)
+			Expect(params["format"]).To(Equal(processor.FormatZip))
+
+			_, _, _, _, options := fakeCompositor.CompositeArgsForCall(0)
+			Expect(options.Format).To(Equal(composite.FormatJpg))
+			Expect(options.Background.Mode).To(Equal(composite.BackgroundFill))
+
+			outputPath, _ := fakeStorage.WriteArgsForCall(0)
+			Expect(outputPath).To(Equal("output-dir/image1.jpg"))
+		})
 	})
 
 	Context("client error", func() {
 		It("keeps processing images", func() {
-			fakeClient.RemoveFromFileReturnsOnCall(0, nil, "", errors.New("boom"))
-			fakeClient.RemoveFromFileReturnsOnCall(1, []byte("Processed2"), mimePng, nil)
+			fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{}, errors.New("boom"))
+			fakeClient.RemoveFromFileReturnsOnCall(1, client.Result{Data: []byte("Processed2"), ContentType: mimePng}, nil)
 			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg"}
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(2))
 			Expect(fakeStorage.WriteCallCount()).To(Equal(1))
@@ -242,16 +407,75 @@ var _ = Describe("Processor", func() {
 		})
 
 		Context("rate limit exceeded", func() {
-			It("stops processing images", func() {
-				rateLimitedExceeded := client.RequestError{
+			var rateLimitedExceeded *client.RequestError
+
+			BeforeEach(func() {
+				rateLimitedExceeded = &client.RequestError{
 					StatusCode: 429,
 					Err:        errors.New("rate limit exceeded"),
+					RetryAfter: time.Millisecond,
 				}
 
-				fakeClient.RemoveFromFileReturnsOnCall(0, nil, "", &rateLimitedExceeded)
+				testSettings.RateLimit = processor.RateLimitSettings{
+					MaxWait:    time.Second,
+					MaxRetries: 2,
+				}
+			})
+
+			It("waits and retries the image", func() {
+				fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{}, rateLimitedExceeded)
+				fakeClient.RemoveFromFileReturnsOnCall(1, client.Result{Data: []byte("Processed1"), ContentType: mimePng}, nil)
+				fakeClient.RemoveFromFileReturnsOnCall(2, client.Result{Data: []byte("Processed2"), ContentType: mimePng}, nil)
+				inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg"}
+
+				subject.Process(context.Background(), inputPaths, testSettings)
+
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(3))
+				Expect(fakeNotifier.RetryCallCount()).To(Equal(1))
+				Expect(fakeNotifier.ErrorCallCount()).To(Equal(0))
+				Expect(fakeNotifier.SuccessCallCount()).To(Equal(2))
+
+				retryErr, retryPath, attempt, delay, imageNumber, total := fakeNotifier.RetryArgsForCall(0)
+				Expect(retryErr).To(Equal(rateLimitedExceeded))
+				Expect(retryPath).To(Equal("dir/image1.jpg"))
+				Expect(attempt).To(Equal(1))
+				Expect(delay).To(Equal(time.Millisecond))
+				Expect(imageNumber).To(Equal(1))
+				Expect(total).To(Equal(2))
+
+				_, retriedPath, _, _ := fakeClient.RemoveFromFileArgsForCall(1)
+				Expect(retriedPath).To(Equal("dir/image1.jpg"))
+			})
+
+			It("stops processing after the maximum retries", func() {
+				fakeClient.RemoveFromFileReturns(client.Result{}, rateLimitedExceeded)
+				inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg"}
+
+				subject.Process(context.Background(), inputPaths, testSettings)
+
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(3))
+				Expect(fakeNotifier.RetryCallCount()).To(Equal(2))
+				Expect(fakeNotifier.ErrorCallCount()).To(Equal(1))
+			})
+
+			It("stops processing if the API asks for a longer wait than allowed", func() {
+				rateLimitedExceeded.RetryAfter = time.Minute
+				fakeClient.RemoveFromFileReturns(client.Result{}, rateLimitedExceeded)
 				inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg"}
 
-				subject.Process(inputPaths, testSettings)
+				subject.Process(context.Background(), inputPaths, testSettings)
+
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
+				Expect(fakeNotifier.RetryCallCount()).To(Equal(0))
+				Expect(fakeNotifier.ErrorCallCount()).To(Equal(1))
+			})
+
+			It("stops processing images when retries are disabled", func() {
+				testSettings.RateLimit = processor.RateLimitSettings{}
+				fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{}, rateLimitedExceeded)
+				inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg"}
+
+				subject.Process(context.Background(), inputPaths, testSettings)
 
 				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
 				Expect(fakeNotifier.ErrorCallCount()).To(Equal(1))
@@ -259,17 +483,131 @@ var _ = Describe("Processor", func() {
 			})
 		})
 
+		Context("multiple API keys", func() {
+			BeforeEach(func() {
+				subject.Keys = processor.NewKeyPool([]string{"key-1", "key-2"})
+				testSettings.RateLimit = processor.RateLimitSettings{
+					MaxWait:    time.Second,
+					MaxRetries: 1,
+				}
+			})
+
+			It("retries with the next key when one runs out of credits", func() {
+				outOfCredits := &client.RequestError{StatusCode: 402, Err: errors.New("Insufficient credits")}
+				fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{}, outOfCredits)
+				fakeClient.RemoveFromFileReturns(client.Result{Data: []byte("Processed"), ContentType: mimePng}, nil)
+				inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg"}
+
+				subject.Process(context.Background(), inputPaths, testSettings)
+
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(3))
+				Expect(fakeNotifier.ErrorCallCount()).To(Equal(0))
+				Expect(fakeNotifier.SuccessCallCount()).To(Equal(2))
+
+				retryErr, _, _, delay, _, _ := fakeNotifier.RetryArgsForCall(0)
+				Expect(retryErr).To(Equal(outOfCredits))
+				Expect(delay).To(Equal(time.Duration(0)))
+
+				_, _, firstKey, _ := fakeClient.RemoveFromFileArgsForCall(0)
+				_, retriedPath, retriedKey, _ := fakeClient.RemoveFromFileArgsForCall(1)
+				_, _, nextKey, _ := fakeClient.RemoveFromFileArgsForCall(2)
+				Expect(firstKey).To(Equal("key-1"))
+				Expect(retriedPath).To(Equal("dir/image1.jpg"))
+				Expect(retriedKey).To(Equal("key-2"))
+				Expect(nextKey).To(Equal("key-2"))
+			})
+
+			It("backs off as usual once every key is rate limited", func() {
+				rateLimitedExceeded := &client.RequestError{
+					StatusCode: 429,
+					Err:        errors.New("rate limit exceeded"),
+					RetryAfter: time.Millisecond,
+				}
+				fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{}, rateLimitedExceeded)
+				fakeClient.RemoveFromFileReturnsOnCall(1, client.Result{}, rateLimitedExceeded)
+				fakeClient.RemoveFromFileReturns(client.Result{Data: []byte("Processed"), ContentType: mimePng}, nil)
+				inputPaths := []string{"dir/image1.jpg"}
+
+				subject.Process(context.Background(), inputPaths, testSettings)
+
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(3))
+				Expect(fakeNotifier.RetryCallCount()).To(Equal(2))
+				Expect(fakeNotifier.SuccessCallCount()).To(Equal(1))
+
+				_, _, _, delay, _, _ := fakeNotifier.RetryArgsForCall(1)
+				Expect(delay).To(Equal(time.Millisecond))
+			})
+		})
+
+		Context("transient error", func() {
+			var serverError *client.RequestError
+
+			BeforeEach(func() {
+				serverError = &client.RequestError{
+					StatusCode: 503,
+					Err:        errors.New("Unable to process image"),
+				}
+
+				testSettings.Retry = processor.RetryPolicy{
+					Attempts:          3,
+					BaseDelay:         time.Millisecond,
+					RetryableStatuses: processor.DefaultRetryableStatuses,
+				}
+			})
+
+			It("retries the image", func() {
+				fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{}, serverError)
+				fakeClient.RemoveFromFileReturnsOnCall(1, client.Result{Data: []byte("Processed1"), ContentType: mimePng}, nil)
+
+				subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
+
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(2))
+				Expect(fakeNotifier.RetryCallCount()).To(Equal(1))
+				Expect(fakeNotifier.SuccessCallCount()).To(Equal(1))
+
+				retryErr, retryPath, attempt, delay, _, _ := fakeNotifier.RetryArgsForCall(0)
+				Expect(retryErr).To(Equal(serverError))
+				Expect(retryPath).To(Equal("dir/image1.jpg"))
+				Expect(attempt).To(Equal(1))
+				Expect(delay).To(Equal(time.Millisecond))
+			})
+
+			It("gives up on the image after the maximum attempts", func() {
+				fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{}, serverError)
+				fakeClient.RemoveFromFileReturnsOnCall(1, client.Result{}, serverError)
+				fakeClient.RemoveFromFileReturnsOnCall(2, client.Result{}, serverError)
+				fakeClient.RemoveFromFileReturnsOnCall(3, client.Result{Data: []byte("Processed2"), ContentType: mimePng}, nil)
+
+				subject.Process(context.Background(), []string{"dir/image1.jpg", "dir/image2.jpg"}, testSettings)
+
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(4))
+				Expect(fakeNotifier.RetryCallCount()).To(Equal(2))
+				Expect(fakeNotifier.ErrorCallCount()).To(Equal(1))
+				Expect(fakeNotifier.SuccessCallCount()).To(Equal(1))
+			})
+
+			It("doesn't retry other errors", func() {
+				fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{}, errors.New("boom"))
+
+				subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
+
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
+				Expect(fakeNotifier.RetryCallCount()).To(Equal(0))
+				Expect(fakeNotifier.ErrorCallCount()).To(Equal(1))
+			})
+		})
+
 		It("passes the error details to the notifier", func() {
 			err := errors.New("boom")
-			fakeClient.RemoveFromFileReturnsOnCall(0, nil, "", err)
-			fakeClient.RemoveFromFileReturnsOnCall(1, []byte("Processed2"), mimePng, nil)
+			fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{}, err)
+			fakeClient.RemoveFromFileReturnsOnCall(1, client.Result{Data: []byte("Processed2"), ContentType: mimePng}, nil)
 			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg"}
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakeNotifier.ErrorCallCount()).To(Equal(1))
 
-			notifiedErr, notifiedPath, notifiedImageNumber, notifiedTotal := fakeNotifier.ErrorArgsForCall(0)
+			notifiedErr, notifiedPath, _, notifiedImageNumber, notifiedTotal := fakeNotifier.ErrorArgsForCall(0)
 
 			Expect(notifiedErr).To(Equal(err))
 			Expect(notifiedPath).To(Equal("dir/image1.jpg"))
@@ -280,12 +618,12 @@ var _ = Describe("Processor", func() {
 
 	Context("writer error", func() {
 		It("keeps processing images", func() {
-			fakeClient.RemoveFromFileReturnsOnCall(0, []byte("Processed1"), mimePng, nil)
-			fakeClient.RemoveFromFileReturnsOnCall(1, []byte("Processed2"), mimePng, nil)
+			fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{Data: []byte("Processed1"), ContentType: mimePng}, nil)
+			fakeClient.RemoveFromFileReturnsOnCall(1, client.Result{Data: []byte("Processed2"), ContentType: mimePng}, nil)
 			fakeStorage.WriteReturnsOnCall(0, errors.New("boom"))
 			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg"}
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(2))
 			Expect(fakeNotifier.ErrorCallCount()).To(Equal(1))
@@ -294,16 +632,16 @@ var _ = Describe("Processor", func() {
 
 		It("passes the error details to the notifier", func() {
 			err := errors.New("boom")
-			fakeClient.RemoveFromFileReturnsOnCall(0, []byte("Processed1"), mimePng, nil)
-			fakeClient.RemoveFromFileReturnsOnCall(1, []byte("Processed2"), mimePng, nil)
+			fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{Data: []byte("Processed1"), ContentType: mimePng}, nil)
+			fakeClient.RemoveFromFileReturnsOnCall(1, client.Result{Data: []byte("Processed2"), ContentType: mimePng}, nil)
 			fakeStorage.WriteReturnsOnCall(0, err)
 			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg"}
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakeNotifier.ErrorCallCount()).To(Equal(1))
 
-			notifiedErr, notifiedPath, notifiedImageNumber, notifiedTotal := fakeNotifier.ErrorArgsForCall(0)
+			notifiedErr, notifiedPath, _, notifiedImageNumber, notifiedTotal := fakeNotifier.ErrorArgsForCall(0)
 
 			Expect(notifiedErr).To(Equal(err))
 			Expect(notifiedPath).To(Equal("dir/image1.jpg"))
@@ -312,6 +650,54 @@ var _ = Describe("Processor", func() {
 		})
 	})
 
+	Describe("concurrency", func() {
+		BeforeEach(func() {
+			testSettings.Concurrency = 4
+			fakeClient.RemoveFromFileReturns(client.Result{Data: []byte("Processed"), ContentType: mimePng}, nil)
+		})
+
+		It("processes every image", func() {
+			inputPaths := []string{"dir/1.jpg", "dir/2.jpg", "dir/3.jpg", "dir/4.jpg", "dir/5.jpg", "dir/6.jpg"}
+
+			subject.Process(context.Background(), inputPaths, testSettings)
+
+			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(6))
+			Expect(fakeStorage.WriteCallCount()).To(Equal(6))
+			Expect(fakeNotifier.SuccessCallCount()).To(Equal(6))
+		})
+
+		It("numbers the images in input order", func() {
+			inputPaths := []string{"dir/1.jpg", "dir/2.jpg", "dir/3.jpg", "dir/4.jpg", "dir/5.jpg", "dir/6.jpg"}
+
+			subject.Process(context.Background(), inputPaths, testSettings)
+
+			numbers := map[string]int{}
+			for i := 0; i < fakeNotifier.SuccessCallCount(); i++ {
+				path, _, _, imageNumber, total := fakeNotifier.SuccessArgsForCall(i)
+				Expect(total).To(Equal(6))
+				numbers[path] = imageNumber
+			}
+
+			Expect(numbers).To(Equal(map[string]int{
+				"dir/1.jpg": 1, "dir/2.jpg": 2, "dir/3.jpg": 3,
+				"dir/4.jpg": 4, "dir/5.jpg": 5, "dir/6.jpg": 6,
+			}))
+		})
+
+		It("stops every worker when the rate limit is exceeded", func() {
+			fakeClient.RemoveFromFileReturns(client.Result{}, &client.RequestError{
+				StatusCode: 429,
+				Err:        errors.New("rate limit exceeded"),
+			})
+			inputPaths := make([]string, 20)
+
+			subject.Process(context.Background(), inputPaths, testSettings)
+
+			Expect(fakeClient.RemoveFromFileCallCount()).To(BeNumerically("<=", testSettings.Concurrency))
+			Expect(fakeStorage.WriteCallCount()).To(Equal(0))
+		})
+	})
+
 	Describe("skipping already processed files", func() {
 		It("skips processing if the output file exists", func() {
 			testSettings.OutputDirectory = ""
@@ -319,7 +705,7 @@ var _ = Describe("Processor", func() {
 			fakeStorage.FileExistsReturnsOnCall(0, true)
 			fakeStorage.FileExistsReturnsOnCall(1, false)
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakeNotifier.SkipCallCount()).To(Equal(1))
 			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
@@ -330,7 +716,7 @@ var _ = Describe("Processor", func() {
 			Expect(notifiedImageNumber).To(Equal(1))
 			Expect(notifiedTotal).To(Equal(2))
 
-			processedPath, _, _ := fakeClient.RemoveFromFileArgsForCall(0)
+			_, processedPath, _, _ := fakeClient.RemoveFromFileArgsForCall(0)
 			Expect(processedPath).To(Equal("dir/image2.jpg"))
 		})
 
@@ -338,7 +724,7 @@ var _ = Describe("Processor", func() {
 			testSettings.ReprocessExisting = true
 			fakeStorage.FileExistsReturns(true)
 
-			subject.Process([]string{"dir/image1.jpg"}, testSettings)
+			subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
 
 			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
 			Expect(fakeStorage.WriteCallCount()).To(Equal(1))
@@ -346,12 +732,237 @@ var _ = Describe("Processor", func() {
 		})
 	})
 
+	Describe("summary", func() {
+		It("counts the processed, skipped and failed images", func() {
+			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg", "dir/image3.jpg", "dir/image4.jpg"}
+			fakeStorage.FileExistsReturnsOnCall(0, true)
+			fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{Data: []byte("Processed2"), ContentType: mimePng}, nil)
+			fakeClient.RemoveFromFileReturnsOnCall(1, client.Result{}, errors.New("boom"))
+			fakeClient.RemoveFromFileReturnsOnCall(2, client.Result{Data: []byte("Processed4"), ContentType: mimePng}, nil)
+
+			summary := subject.Process(context.Background(), inputPaths, testSettings)
+
+			Expect(summary.Total).To(Equal(4))
+			Expect(summary.Processed).To(Equal(2))
+			Expect(summary.Skipped).To(Equal(1))
+			Expect(summary.Failed).To(Equal(1))
+			Expect(summary.Duration).To(BeNumerically(">", 0))
+		})
+
+		It("lists the errors for each failed image", func() {
+			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg"}
+			fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{}, errors.New("boom"))
+			fakeClient.RemoveFromFileReturnsOnCall(1, client.Result{}, &client.RequestError{StatusCode: 400, Err: errors.New("File too large")})
+
+			summary := subject.Process(context.Background(), inputPaths, testSettings)
+
+			Expect(summary.Errors).To(Equal([]processor.FileError{
+				{Input: "dir/image1.jpg", Output: "output-dir/image1.png", Message: "boom"},
+				{Input: "dir/image2.jpg", Output: "output-dir/image2.png", StatusCode: 400, Message: "400: File too large"},
+			}))
+			Expect(summary.RateLimited).To(BeFalse())
+			Expect(summary.AuthFailed).To(BeFalse())
+		})
+
+		It("stops the batch when the API key is rejected", func() {
+			fakeClient.RemoveFromFileReturns(client.Result{}, &client.RequestError{StatusCode: 403, Err: errors.New("API Key invalid")})
+			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg", "dir/image3.jpg"}
+
+			summary := subject.Process(context.Background(), inputPaths, testSettings)
+
+			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
+			Expect(summary.AuthFailed).To(BeTrue())
+			Expect(summary.Remaining()).To(Equal(2))
+		})
+
+		It("counts the images not started when the batch halts", func() {
+			fakeClient.RemoveFromFileReturns(client.Result{}, &client.RequestError{StatusCode: 429, Err: errors.New("rate limit exceeded")})
+			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg", "dir/image3.jpg"}
+
+			summary := subject.Process(context.Background(), inputPaths, testSettings)
+
+			Expect(summary.Failed).To(Equal(1))
+			Expect(summary.Remaining()).To(Equal(2))
+			Expect(summary.Cancelled).To(BeFalse())
+			Expect(summary.RateLimited).To(BeTrue())
+		})
+	})
+
+	Describe("credit budget", func() {
+		BeforeEach(func() {
+			fakeClient.RemoveFromFileReturns(client.Result{Data: []byte("Processed"), ContentType: mimePng, CreditsCharged: 1}, nil)
+		})
+
+		It("stops once the next image would exceed the budget", func() {
+			testSettings.MaxCredits = 2.5
+			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg", "dir/image3.jpg", "dir/image4.jpg"}
+
+			summary := subject.Process(context.Background(), inputPaths, testSettings)
+
+			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(2))
+			Expect(summary.Processed).To(Equal(2))
+			Expect(summary.Credits).To(Equal(2.0))
+			Expect(summary.BudgetExceeded).To(BeTrue())
+			Expect(summary.Remaining()).To(Equal(2))
+		})
+
+		It("uses the credits actually charged", func() {
+			fakeClient.RemoveFromFileReturns(client.Result{Data: []byte("Processed"), ContentType: mimePng, CreditsCharged: 0}, nil)
+			testSettings.MaxCredits = 1
+			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg", "dir/image3.jpg"}
+
+			summary := subject.Process(context.Background(), inputPaths, testSettings)
+
+			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(3))
+			Expect(summary.BudgetExceeded).To(BeFalse())
+		})
+
+		It("expects each image to cost the most charged so far, not the estimate", func() {
+			fakeClient.RemoveFromFileReturns(client.Result{Data: []byte("Processed"), ContentType: mimePng, CreditsCharged: 0.25}, nil)
+			testSettings.MaxCredits = 1
+			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg", "dir/image3.jpg", "dir/image4.jpg", "dir/image5.jpg"}
+
+			summary := subject.Process(context.Background(), inputPaths, testSettings)
+
+			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(4))
+			Expect(summary.Credits).To(Equal(1.0))
+			Expect(summary.BudgetExceeded).To(BeTrue())
+		})
+
+		It("is unlimited by default", func() {
+			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg", "dir/image3.jpg"}
+
+			summary := subject.Process(context.Background(), inputPaths, testSettings)
+
+			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(3))
+			Expect(summary.Credits).To(Equal(3.0))
+			Expect(summary.BudgetExceeded).To(BeFalse())
+		})
+	})
+
+	Describe("cancellation", func() {
+		It("stops starting new images", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			fakeClient.RemoveFromFileStub = func(context.Context, string, string, map[string]string) (client.Result, error) {
+				cancel()
+				return client.Result{Data: []byte("Processed"), ContentType: mimePng}, nil
+			}
+			inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg", "dir/image3.jpg"}
+
+			summary := subject.Process(ctx, inputPaths, testSettings)
+
+			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
+			Expect(fakeStorage.WriteCallCount()).To(Equal(1))
+			Expect(summary.Cancelled).To(BeTrue())
+			Expect(summary.Processed).To(Equal(1))
+			Expect(summary.Remaining()).To(Equal(2))
+		})
+
+		It("passes the context to the client", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			subject.Process(ctx, []string{"dir/image1.jpg"}, testSettings)
+
+			clientCtx, _, _, _ := fakeClient.RemoveFromFileArgsForCall(0)
+			Expect(clientCtx).To(Equal(ctx))
+		})
+
+		It("doesn't retry requests abandoned by the cancellation", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			testSettings.Retry = processor.RetryPolicy{Attempts: 3, RetryNetworkErrors: true}
+			fakeClient.RemoveFromFileStub = func(context.Context, string, string, map[string]string) (client.Result, error) {
+				cancel()
+				return client.Result{}, &url.Error{Op: "Post", Err: context.Canceled}
+			}
+
+			summary := subject.Process(ctx, []string{"dir/image1.jpg"}, testSettings)
+
+			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
+			Expect(fakeNotifier.RetryCallCount()).To(Equal(0))
+			Expect(fakeNotifier.ErrorCallCount()).To(Equal(1))
+			Expect(summary.Failed).To(Equal(1))
+		})
+	})
+
+	Describe("journal", func() {
+		var fakeJournal *processorfakes.FakeJournalInterface
+
+		BeforeEach(func() {
+			fakeJournal = &processorfakes.FakeJournalInterface{}
+			subject.Journal = fakeJournal
+			fakeStorage.ChecksumStub = func(path string) (string, error) {
+				return "hash-of-" + path, nil
+			}
+		})
+
+		It("records the outcome of each image", func() {
+			fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{Data: []byte("Processed1"), ContentType: mimePng, CreditsCharged: 1}, nil)
+			fakeClient.RemoveFromFileReturnsOnCall(1, client.Result{}, errors.New("boom"))
+
+			subject.Process(context.Background(), []string{"dir/image1.jpg", "dir/image2.jpg"}, testSettings)
+
+			Expect(fakeJournal.RecordCallCount()).To(Equal(2))
+
+			processed := fakeJournal.RecordArgsForCall(0)
+			Expect(processed.Input).To(Equal("dir/image1.jpg"))
+			Expect(processed.Output).To(Equal("output-dir/image1.png"))
+			Expect(processed.InputHash).To(Equal("hash-of-dir/image1.jpg"))
+			Expect(processed.Status).To(Equal(processor.JournalProcessed))
+			Expect(processed.Credits).To(Equal(1.0))
+
+			failed := fakeJournal.RecordArgsForCall(1)
+			Expect(failed.Input).To(Equal("dir/image2.jpg"))
+			Expect(failed.Status).To(Equal(processor.JournalFailed))
+			Expect(failed.Error).To(Equal("boom"))
+		})
+
+		It("doesn't load the journal unless resuming", func() {
+			subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
+
+			Expect(fakeJournal.LoadCallCount()).To(Equal(0))
+		})
+
+		Context("resuming", func() {
+			BeforeEach(func() {
+				testSettings.Resume = true
+				fakeJournal.LoadReturns(map[string]processor.JournalEntry{
+					"dir/image1.jpg": {Input: "dir/image1.jpg", Output: "elsewhere/image1.png", InputHash: "hash-of-dir/image1.jpg", Status: processor.JournalProcessed},
+					"dir/image2.jpg": {Input: "dir/image2.jpg", Output: "output-dir/image2.png", InputHash: "hash-of-dir/image2.jpg", Status: processor.JournalFailed},
+					"dir/image3.jpg": {Input: "dir/image3.jpg", Output: "output-dir/image3.png", InputHash: "stale-hash", Status: processor.JournalProcessed},
+				}, nil)
+			})
+
+			It("skips images the journal recorded as processed", func() {
+				inputPaths := []string{"dir/image1.jpg", "dir/image2.jpg", "dir/image3.jpg", "dir/image4.jpg"}
+
+				subject.Process(context.Background(), inputPaths, testSettings)
+
+				Expect(fakeNotifier.SkipCallCount()).To(Equal(1))
+				skippedInput, existing, _, _ := fakeNotifier.SkipArgsForCall(0)
+				Expect(skippedInput).To(Equal("dir/image1.jpg"))
+				Expect(existing).To(Equal("elsewhere/image1.png"))
+
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(3))
+			})
+
+			It("reprocesses outputs the journal didn't record as complete", func() {
+				fakeStorage.FileExistsReturns(true)
+
+				subject.Process(context.Background(), []string{"dir/image2.jpg"}, testSettings)
+
+				Expect(fakeNotifier.SkipCallCount()).To(Equal(0))
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
+			})
+		})
+	})
+
 	Describe("large batch confirmation", func() {
 		It("doesn't prompt under the limit", func() {
 			inputPaths := []string{"dir/image1.jpg"}
 			testSettings.LargeBatchConfirmThreshold = 50
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakePrompt.ConfirmLargeBatchCallCount()).To(Equal(0))
 		})
@@ -361,17 +972,59 @@ var _ = Describe("Processor", func() {
 			inputPaths := make([]string, 50)
 			testSettings.LargeBatchConfirmThreshold = 50
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakePrompt.ConfirmLargeBatchCallCount()).To(Equal(1))
 			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(50))
 		})
 
+		It("shows the estimated credits and account balance", func() {
+			fakeClient.AccountReturns(client.Account{TotalCredits: 200}, nil)
+			inputPaths := make([]string, 60)
+			testSettings.ImageSettings.Size = "preview"
+
+			subject.Process(context.Background(), inputPaths, testSettings)
+
+			size, estimate := fakePrompt.ConfirmLargeBatchArgsForCall(0)
+			Expect(size).To(Equal(60))
+			Expect(estimate).To(Equal(processor.CreditEstimate{
+				Credits:      15,
+				Balance:      200,
+				BalanceKnown: true,
+			}))
+		})
+
+		It("starts the progress clock once the batch is confirmed", func() {
+			now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			progress := &processor.ProgressNotifier{Writer: ioutil.Discard, Now: func() time.Time { return now }}
+			subject.Notifier = progress
+			fakePrompt.ConfirmLargeBatchStub = func(int, processor.CreditEstimate) bool {
+				now = now.Add(10 * time.Minute)
+				return true
+			}
+			inputPaths := make([]string, 60)
+
+			subject.Process(context.Background(), inputPaths, testSettings)
+
+			Expect(progress.StartedAt).To(Equal(time.Date(2020, 1, 1, 0, 10, 0, 0, time.UTC)))
+		})
+
+		It("still prompts if the account balance is unavailable", func() {
+			fakeClient.AccountReturns(client.Account{}, errors.New("boom"))
+			inputPaths := make([]string, 60)
+
+			subject.Process(context.Background(), inputPaths, testSettings)
+
+			_, estimate := fakePrompt.ConfirmLargeBatchArgsForCall(0)
+			Expect(estimate.Credits).To(Equal(60.0))
+			Expect(estimate.BalanceKnown).To(BeFalse())
+		})
+
 		It("can be skipped with a negative value", func() {
 			inputPaths := make([]string, 50)
 			testSettings.LargeBatchConfirmThreshold = -1
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakePrompt.ConfirmLargeBatchCallCount()).To(Equal(0))
 			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(50))
@@ -382,7 +1035,7 @@ var _ = Describe("Processor", func() {
 			inputPaths := make([]string, 25)
 			testSettings.LargeBatchConfirmThreshold = 25
 
-			subject.Process(inputPaths, testSettings)
+			subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakePrompt.ConfirmLargeBatchCallCount()).To(Equal(1))
 			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(25))
@@ -393,10 +1046,11 @@ var _ = Describe("Processor", func() {
 			inputPaths := make([]string, 50)
 			testSettings.LargeBatchConfirmThreshold = 50
 
-			subject.Process(inputPaths, testSettings)
+			summary := subject.Process(context.Background(), inputPaths, testSettings)
 
 			Expect(fakePrompt.ConfirmLargeBatchCallCount()).To(Equal(1))
 			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(0))
+			Expect(summary.Cancelled).To(BeTrue())
 		})
 	})
 
@@ -412,4 +1066,261 @@ var _ = Describe("Processor", func() {
 			Expect(p.Compositor).ToNot(BeNil())
 		})
 	})
+
+	Describe("mask cache", func() {
+		var (
+			fakeMaskCache *processorfakes.FakeMaskCacheInterface
+			zipData       []byte
+		)
+
+		BeforeEach(func() {
+			fakeMaskCache = &processorfakes.FakeMaskCacheInterface{}
+			subject.MaskCache = fakeMaskCache
+			fakeStorage.ChecksumStub = func(path string) (string, error) {
+				return "hash-of-" + path, nil
+			}
+
+			var err error
+			zipData, err = ioutil.ReadFile("../fixtures/zip/example-cat.zip")
+			Expect(err).ToNot(HaveOccurred())
+
+			testSettings.ImageSettings = processor.ImageSettings{Size: "full", OutputFormat: "png"}
+		})
+
+		It("caches the mask from the ZIP, keyed by the input and params", func() {
+			fakeClient.RemoveFromFileReturns(client.Result{Data: zipData, ContentType: processor.MimeZip}, nil)
+
+			subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
+
+			Expect(fakeMaskCache.PutCallCount()).To(Equal(1))
+			key, mask := fakeMaskCache.PutArgsForCall(0)
+			Expect(key).To(Equal(processor.MaskCacheKey("hash-of-dir/image1.jpg", map[string]string{"size": "full"})))
+
+			expectedMask, err := composite.ReadMask(bytes.NewReader(zipData), int64(len(zipData)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mask).To(Equal(expectedMask))
+		})
+
+		It("requests a ZIP for other formats, compositing it here", func() {
+			testSettings.ImageSettings.OutputFormat = "jpg"
+			testSettings.SkipPngFormatOptimization = true
+			fakeClient.RemoveFromFileReturns(client.Result{Data: zipData, ContentType: processor.MimeZip}, nil)
+
+			subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
+
+			_, _, _, params := fakeClient.RemoveFromFileArgsForCall(0)
+			Expect(params["format"]).To(Equal(processor.FormatZip))
+			Expect(fakeMaskCache.PutCallCount()).To(Equal(1))
+
+			_, _, _, _, options := fakeCompositor.CompositeArgsForCall(0)
+			Expect(options.Format).To(Equal(composite.FormatJpg))
+			outputPath, _ := fakeStorage.WriteArgsForCall(0)
+			Expect(outputPath).To(Equal("output-dir/image1.jpg"))
+		})
+
+		It("doesn't cache images the API didn't return as a ZIP", func() {
+			testSettings.ImageSettings.OutputFormat = "jpg"
+			fakeClient.RemoveFromFileReturns(client.Result{Data: []byte("jpg"), ContentType: "image/jpeg"}, nil)
+
+			subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
+
+			Expect(fakeMaskCache.PutCallCount()).To(Equal(0))
+		})
+
+		It("doesn't cache URLs", func() {
+			fakeClient.RemoveFromURLReturns(client.Result{Data: zipData, ContentType: processor.MimeZip}, nil)
+
+			subject.Process(context.Background(), []string{"https://example.com/image1.jpg"}, testSettings)
+
+			Expect(fakeMaskCache.PutCallCount()).To(Equal(0))
+		})
+
+		It("doesn't cache failed images", func() {
+			fakeClient.RemoveFromFileReturns(client.Result{}, errors.New("boom"))
+
+			subject.Process(context.Background(), []string{"dir/image1.jpg"}, testSettings)
+
+			Expect(fakeMaskCache.PutCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("deduplication", func() {
+		BeforeEach(func() {
+			testSettings.Dedupe = true
+			fakeStorage.ChecksumStub = func(path string) (string, error) {
+				if path == "dir/c.jpg" {
+					return "other-hash", nil
+				}
+
+				return "same-hash", nil
+			}
+
+			fakeClient.RemoveFromFileReturns(client.Result{Data: []byte("Processed"), ContentType: mimePng, CreditsCharged: 1}, nil)
+		})
+
+		It("processes identical images once, copying the output to the others", func() {
+			summary := subject.Process(context.Background(), []string{"dir/a.jpg", "dir/b.jpg", "dir/c.jpg"}, testSettings)
+
+			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(2))
+			Expect(fakeStorage.CopyCallCount()).To(Equal(1))
+			source, destination := fakeStorage.CopyArgsForCall(0)
+			Expect(source).To(Equal("output-dir/a.png"))
+			Expect(destination).To(Equal("output-dir/b.png"))
+
+			Expect(fakeNotifier.DuplicateCallCount()).To(Equal(1))
+			input, output, original, imageNumber, _ := fakeNotifier.DuplicateArgsForCall(0)
+			Expect(input).To(Equal("dir/b.jpg"))
+			Expect(output).To(Equal("output-dir/b.png"))
+			Expect(original).To(Equal("dir/a.jpg"))
+			Expect(imageNumber).To(Equal(2))
+
+			Expect(summary.Processed).To(Equal(3))
+			Expect(summary.Duplicates).To(Equal(1))
+			Expect(summary.Credits).To(Equal(2.0))
+			Expect(summary.CreditsSaved).To(Equal(1.0))
+		})
+
+		It("waits for the original when processing in parallel", func() {
+			testSettings.Concurrency = 3
+			fakeClient.RemoveFromFileStub = func(ctx context.Context, path string, apiKey string, params map[string]string) (client.Result, error) {
+				time.Sleep(10 * time.Millisecond)
+				return client.Result{Data: []byte("Processed"), ContentType: mimePng, CreditsCharged: 1}, nil
+			}
+
+			summary := subject.Process(context.Background(), []string{"dir/a.jpg", "dir/b.jpg", "dir/d.jpg"}, testSettings)
+
+			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
+			Expect(fakeStorage.CopyCallCount()).To(Equal(2))
+			Expect(summary.Duplicates).To(Equal(2))
+		})
+
+		It("processes a duplicate itself if the original failed", func() {
+			fakeClient.RemoveFromFileReturnsOnCall(0, client.Result{}, errors.New("boom"))
+
+			summary := subject.Process(context.Background(), []string{"dir/a.jpg", "dir/b.jpg"}, testSettings)
+
+			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(2))
+			Expect(fakeStorage.CopyCallCount()).To(Equal(0))
+			Expect(summary.Failed).To(Equal(1))
+			Expect(summary.Processed).To(Equal(1))
+			Expect(summary.Duplicates).To(Equal(0))
+		})
+
+		It("fails the duplicate if the output can't be copied", func() {
+			fakeStorage.CopyReturns(errors.New("disk full"))
+
+			summary := subject.Process(context.Background(), []string{"dir/a.jpg", "dir/b.jpg"}, testSettings)
+
+			Expect(summary.Failed).To(Equal(1))
+			Expect(summary.Errors[0].Input).To(Equal("dir/b.jpg"))
+			Expect(summary.Errors[0].Message).To(Equal("disk full"))
+		})
+
+		It("can be disabled", func() {
+			testSettings.Dedupe = false
+
+			subject.Process(context.Background(), []string{"dir/a.jpg", "dir/b.jpg"}, testSettings)
+
+			Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(2))
+			Expect(fakeStorage.CopyCallCount()).To(Equal(0))
+		})
+
+		Context("across batches", func() {
+			var fakeJournal *processorfakes.FakeJournalInterface
+
+			BeforeEach(func() {
+				fakeJournal = &processorfakes.FakeJournalInterface{}
+				subject.Journal = fakeJournal
+			})
+
+			// The journal entries of a previous batch processing dir/a.jpg,
+			// which left its output behind
+			previousBatch := func(settings processor.Settings) map[string]processor.JournalEntry {
+				subject.Process(context.Background(), []string{"dir/a.jpg"}, settings)
+
+				fakeStorage.FileExistsStub = func(path string) bool {
+					return path == "output-dir/a.png"
+				}
+
+				entry := fakeJournal.RecordArgsForCall(fakeJournal.RecordCallCount() - 1)
+				return map[string]processor.JournalEntry{entry.Input: entry}
+			}
+
+			It("copies the output of an identical image processed with the same settings", func() {
+				fakeJournal.LoadReturns(previousBatch(testSettings), nil)
+
+				summary := subject.Process(context.Background(), []string{"dir/b.jpg"}, testSettings)
+
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
+				source, destination := fakeStorage.CopyArgsForCall(0)
+				Expect(source).To(Equal("output-dir/a.png"))
+				Expect(destination).To(Equal("output-dir/b.png"))
+				Expect(summary.CreditsSaved).To(Equal(1.0))
+
+				duplicate := fakeJournal.RecordArgsForCall(1)
+				Expect(duplicate.Input).To(Equal("dir/b.jpg"))
+				Expect(duplicate.InputHash).To(Equal("same-hash"))
+				Expect(duplicate.Status).To(Equal(processor.JournalProcessed))
+			})
+
+			It("reprocesses images previously processed with other settings", func() {
+				previousSettings := testSettings
+				previousSettings.ImageSettings.Size = "full"
+				fakeJournal.LoadReturns(previousBatch(previousSettings), nil)
+
+				subject.Process(context.Background(), []string{"dir/b.jpg"}, testSettings)
+
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(2))
+				Expect(fakeStorage.CopyCallCount()).To(Equal(0))
+			})
+
+			It("reprocesses the same image when reprocessing existing outputs", func() {
+				fakeJournal.LoadReturns(previousBatch(testSettings), nil)
+				testSettings.ReprocessExisting = true
+
+				summary := subject.Process(context.Background(), []string{"dir/a.jpg"}, testSettings)
+
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(2))
+				Expect(fakeStorage.CopyCallCount()).To(Equal(0))
+				Expect(summary.Processed).To(Equal(1))
+				Expect(summary.Duplicates).To(Equal(0))
+			})
+
+			It("reprocesses identical images whose output is being overwritten", func() {
+				fakeJournal.LoadReturns(previousBatch(testSettings), nil)
+				fakeStorage.FileExistsReturns(true)
+				fakeStorage.FileExistsStub = nil
+				testSettings.ReprocessExisting = true
+
+				summary := subject.Process(context.Background(), []string{"dir/b.jpg"}, testSettings)
+
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(2))
+				Expect(fakeStorage.CopyCallCount()).To(Equal(0))
+				Expect(summary.Duplicates).To(Equal(0))
+			})
+
+			It("only reads the journal once when the index is shared between calls", func() {
+				subject.DedupeIndex = processor.NewDedupeIndex()
+
+				subject.Process(context.Background(), []string{"dir/a.jpg"}, testSettings)
+				summary := subject.Process(context.Background(), []string{"dir/b.jpg"}, testSettings)
+
+				Expect(fakeJournal.LoadCallCount()).To(Equal(1))
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(1))
+				source, destination := fakeStorage.CopyArgsForCall(0)
+				Expect(source).To(Equal("output-dir/a.png"))
+				Expect(destination).To(Equal("output-dir/b.png"))
+				Expect(summary.Duplicates).To(Equal(1))
+			})
+
+			It("reprocesses images whose previous output was removed", func() {
+				fakeJournal.LoadReturns(previousBatch(testSettings), nil)
+				fakeStorage.FileExistsStub = nil
+
+				subject.Process(context.Background(), []string{"dir/b.jpg"}, testSettings)
+
+				Expect(fakeClient.RemoveFromFileCallCount()).To(Equal(2))
+			})
+		})
+	})
 })
@@ -0,0 +1,181 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package processorfakes
+
+import (
+	"sync"
+
+	"github.com/remove-bg/go/processor"
+)
+
+type FakeJournalInterface struct {
+	LoadStub        func() (map[string]processor.JournalEntry, error)
+	loadMutex       sync.RWMutex
+	loadArgsForCall []struct {
+	}
+	loadReturns struct {
+		result1 map[string]processor.JournalEntry
+		result2 error
+	}
+	loadReturnsOnCall map[int]struct {
+		result1 map[string]processor.JournalEntry
+		result2 error
+	}
+	RecordStub        func(processor.JournalEntry) error
+	recordMutex       sync.RWMutex
+	recordArgsForCall []struct {
+		arg1 processor.JournalEntry
+	}
+	recordReturns struct {
+		result1 error
+	}
+	recordReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeJournalInterface) Load() (map[string]processor.JournalEntry, error) {
+	fake.loadMutex.Lock()
+	ret, specificReturn := fake.loadReturnsOnCall[len(fake.loadArgsForCall)]
+	fake.loadArgsForCall = append(fake.loadArgsForCall, struct {
+	}{})
+	stub := fake.LoadStub
+	fakeReturns := fake.loadReturns
+	fake.recordInvocation("Load", []interface{}{})
+	fake.loadMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeJournalInterface) LoadCallCount() int {
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	return len(fake.loadArgsForCall)
+}
+
+func (fake *FakeJournalInterface) LoadCalls(stub func() (map[string]processor.JournalEntry, error)) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = stub
+}
+
+func (fake *FakeJournalInterface) LoadReturns(result1 map[string]processor.JournalEntry, result2 error) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = nil
+	fake.loadReturns = struct {
+		result1 map[string]processor.JournalEntry
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeJournalInterface) LoadReturnsOnCall(i int, result1 map[string]processor.JournalEntry, result2 error) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = nil
+	if fake.loadReturnsOnCall == nil {
+		fake.loadReturnsOnCall = make(map[int]struct {
+			result1 map[string]processor.JournalEntry
+			result2 error
+		})
+	}
+	fake.loadReturnsOnCall[i] = struct {
+		result1 map[string]processor.JournalEntry
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeJournalInterface) Record(arg1 processor.JournalEntry) error {
+	fake.recordMutex.Lock()
+	ret, specificReturn := fake.recordReturnsOnCall[len(fake.recordArgsForCall)]
+	fake.recordArgsForCall = append(fake.recordArgsForCall, struct {
+		arg1 processor.JournalEntry
+	}{arg1})
+	stub := fake.RecordStub
+	fakeReturns := fake.recordReturns
+	fake.recordInvocation("Record", []interface{}{arg1})
+	fake.recordMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeJournalInterface) RecordCallCount() int {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	return len(fake.recordArgsForCall)
+}
+
+func (fake *FakeJournalInterface) RecordCalls(stub func(processor.JournalEntry) error) {
+	fake.recordMutex.Lock()
+	defer fake.recordMutex.Unlock()
+	fake.RecordStub = stub
+}
+
+func (fake *FakeJournalInterface) RecordArgsForCall(i int) processor.JournalEntry {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	argsForCall := fake.recordArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeJournalInterface) RecordReturns(result1 error) {
+	fake.recordMutex.Lock()
+	defer fake.recordMutex.Unlock()
+	fake.RecordStub = nil
+	fake.recordReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeJournalInterface) RecordReturnsOnCall(i int, result1 error) {
+	fake.recordMutex.Lock()
+	defer fake.recordMutex.Unlock()
+	fake.RecordStub = nil
+	if fake.recordReturnsOnCall == nil {
+		fake.recordReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.recordReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeJournalInterface) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeJournalInterface) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ processor.JournalInterface = new(FakeJournalInterface)
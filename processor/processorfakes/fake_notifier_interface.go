@@ -3,18 +3,40 @@ package processorfakes
 
 import (
 	"sync"
+	"time"
 
+	"github.com/remove-bg/go/client"
 	"github.com/remove-bg/go/processor"
 )
 
 type FakeNotifierInterface struct {
-	ErrorStub        func(error, string, int, int)
+	DuplicateStub        func(string, string, string, int, int)
+	duplicateMutex       sync.RWMutex
+	duplicateArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 int
+		arg5 int
+	}
+	ErrorStub        func(error, string, string, int, int)
 	errorMutex       sync.RWMutex
 	errorArgsForCall []struct {
 		arg1 error
 		arg2 string
-		arg3 int
+		arg3 string
 		arg4 int
+		arg5 int
+	}
+	RetryStub        func(error, string, int, time.Duration, int, int)
+	retryMutex       sync.RWMutex
+	retryArgsForCall []struct {
+		arg1 error
+		arg2 string
+		arg3 int
+		arg4 time.Duration
+		arg5 int
+		arg6 int
 	}
 	SkipStub        func(string, string, int, int)
 	skipMutex       sync.RWMutex
@@ -24,29 +46,69 @@ type FakeNotifierInterface struct {
 		arg3 int
 		arg4 int
 	}
-	SuccessStub        func(string, int, int)
+	SuccessStub        func(string, string, client.Result, int, int)
 	successMutex       sync.RWMutex
 	successArgsForCall []struct {
 		arg1 string
-		arg2 int
-		arg3 int
+		arg2 string
+		arg3 client.Result
+		arg4 int
+		arg5 int
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeNotifierInterface) Error(arg1 error, arg2 string, arg3 int, arg4 int) {
+func (fake *FakeNotifierInterface) Duplicate(arg1 string, arg2 string, arg3 string, arg4 int, arg5 int) {
+	fake.duplicateMutex.Lock()
+	fake.duplicateArgsForCall = append(fake.duplicateArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 int
+		arg5 int
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.DuplicateStub
+	fake.recordInvocation("Duplicate", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.duplicateMutex.Unlock()
+	if stub != nil {
+		fake.DuplicateStub(arg1, arg2, arg3, arg4, arg5)
+	}
+}
+
+func (fake *FakeNotifierInterface) DuplicateCallCount() int {
+	fake.duplicateMutex.RLock()
+	defer fake.duplicateMutex.RUnlock()
+	return len(fake.duplicateArgsForCall)
+}
+
+func (fake *FakeNotifierInterface) DuplicateCalls(stub func(string, string, string, int, int)) {
+	fake.duplicateMutex.Lock()
+	defer fake.duplicateMutex.Unlock()
+	fake.DuplicateStub = stub
+}
+
+func (fake *FakeNotifierInterface) DuplicateArgsForCall(i int) (string, string, string, int, int) {
+	fake.duplicateMutex.RLock()
+	defer fake.duplicateMutex.RUnlock()
+	argsForCall := fake.duplicateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeNotifierInterface) Error(arg1 error, arg2 string, arg3 string, arg4 int, arg5 int) {
 	fake.errorMutex.Lock()
 	fake.errorArgsForCall = append(fake.errorArgsForCall, struct {
 		arg1 error
 		arg2 string
-		arg3 int
+		arg3 string
 		arg4 int
-	}{arg1, arg2, arg3, arg4})
-	fake.recordInvocation("Error", []interface{}{arg1, arg2, arg3, arg4})
+		arg5 int
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.ErrorStub
+	fake.recordInvocation("Error", []interface{}{arg1, arg2, arg3, arg4, arg5})
 	fake.errorMutex.Unlock()
-	if fake.ErrorStub != nil {
-		fake.ErrorStub(arg1, arg2, arg3, arg4)
+	if stub != nil {
+		fake.ErrorStub(arg1, arg2, arg3, arg4, arg5)
 	}
 }
 
@@ -56,17 +118,54 @@ func (fake *FakeNotifierInterface) ErrorCallCount() int {
 	return len(fake.errorArgsForCall)
 }
 
-func (fake *FakeNotifierInterface) ErrorCalls(stub func(error, string, int, int)) {
+func (fake *FakeNotifierInterface) ErrorCalls(stub func(error, string, string, int, int)) {
 	fake.errorMutex.Lock()
 	defer fake.errorMutex.Unlock()
 	fake.ErrorStub = stub
 }
 
-func (fake *FakeNotifierInterface) ErrorArgsForCall(i int) (error, string, int, int) {
+func (fake *FakeNotifierInterface) ErrorArgsForCall(i int) (error, string, string, int, int) {
 	fake.errorMutex.RLock()
 	defer fake.errorMutex.RUnlock()
 	argsForCall := fake.errorArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeNotifierInterface) Retry(arg1 error, arg2 string, arg3 int, arg4 time.Duration, arg5 int, arg6 int) {
+	fake.retryMutex.Lock()
+	fake.retryArgsForCall = append(fake.retryArgsForCall, struct {
+		arg1 error
+		arg2 string
+		arg3 int
+		arg4 time.Duration
+		arg5 int
+		arg6 int
+	}{arg1, arg2, arg3, arg4, arg5, arg6})
+	stub := fake.RetryStub
+	fake.recordInvocation("Retry", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6})
+	fake.retryMutex.Unlock()
+	if stub != nil {
+		fake.RetryStub(arg1, arg2, arg3, arg4, arg5, arg6)
+	}
+}
+
+func (fake *FakeNotifierInterface) RetryCallCount() int {
+	fake.retryMutex.RLock()
+	defer fake.retryMutex.RUnlock()
+	return len(fake.retryArgsForCall)
+}
+
+func (fake *FakeNotifierInterface) RetryCalls(stub func(error, string, int, time.Duration, int, int)) {
+	fake.retryMutex.Lock()
+	defer fake.retryMutex.Unlock()
+	fake.RetryStub = stub
+}
+
+func (fake *FakeNotifierInterface) RetryArgsForCall(i int) (error, string, int, time.Duration, int, int) {
+	fake.retryMutex.RLock()
+	defer fake.retryMutex.RUnlock()
+	argsForCall := fake.retryArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6
 }
 
 func (fake *FakeNotifierInterface) Skip(arg1 string, arg2 string, arg3 int, arg4 int) {
@@ -77,9 +176,10 @@ func (fake *FakeNotifierInterface) Skip(arg1 string, arg2 string, arg3 int, arg4
 		arg3 int
 		arg4 int
 	}{arg1, arg2, arg3, arg4})
+	stub := fake.SkipStub
 	fake.recordInvocation("Skip", []interface{}{arg1, arg2, arg3, arg4})
 	fake.skipMutex.Unlock()
-	if fake.SkipStub != nil {
+	if stub != nil {
 		fake.SkipStub(arg1, arg2, arg3, arg4)
 	}
 }
@@ -103,17 +203,20 @@ func (fake *FakeNotifierInterface) SkipArgsForCall(i int) (string, string, int,
 	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
-func (fake *FakeNotifierInterface) Success(arg1 string, arg2 int, arg3 int) {
+func (fake *FakeNotifierInterface) Success(arg1 string, arg2 string, arg3 client.Result, arg4 int, arg5 int) {
 	fake.successMutex.Lock()
 	fake.successArgsForCall = append(fake.successArgsForCall, struct {
 		arg1 string
-		arg2 int
-		arg3 int
-	}{arg1, arg2, arg3})
-	fake.recordInvocation("Success", []interface{}{arg1, arg2, arg3})
+		arg2 string
+		arg3 client.Result
+		arg4 int
+		arg5 int
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.SuccessStub
+	fake.recordInvocation("Success", []interface{}{arg1, arg2, arg3, arg4, arg5})
 	fake.successMutex.Unlock()
-	if fake.SuccessStub != nil {
-		fake.SuccessStub(arg1, arg2, arg3)
+	if stub != nil {
+		fake.SuccessStub(arg1, arg2, arg3, arg4, arg5)
 	}
 }
 
@@ -123,24 +226,28 @@ func (fake *FakeNotifierInterface) SuccessCallCount() int {
 	return len(fake.successArgsForCall)
 }
 
-func (fake *FakeNotifierInterface) SuccessCalls(stub func(string, int, int)) {
+func (fake *FakeNotifierInterface) SuccessCalls(stub func(string, string, client.Result, int, int)) {
 	fake.successMutex.Lock()
 	defer fake.successMutex.Unlock()
 	fake.SuccessStub = stub
 }
 
-func (fake *FakeNotifierInterface) SuccessArgsForCall(i int) (string, int, int) {
+func (fake *FakeNotifierInterface) SuccessArgsForCall(i int) (string, string, client.Result, int, int) {
 	fake.successMutex.RLock()
 	defer fake.successMutex.RUnlock()
 	argsForCall := fake.successArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
 func (fake *FakeNotifierInterface) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.duplicateMutex.RLock()
+	defer fake.duplicateMutex.RUnlock()
 	fake.errorMutex.RLock()
 	defer fake.errorMutex.RUnlock()
+	fake.retryMutex.RLock()
+	defer fake.retryMutex.RUnlock()
 	fake.skipMutex.RLock()
 	defer fake.skipMutex.RUnlock()
 	fake.successMutex.RLock()
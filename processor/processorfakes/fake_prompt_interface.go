@@ -8,10 +8,11 @@ import (
 )
 
 type FakePromptInterface struct {
-	ConfirmLargeBatchStub        func(int) bool
+	ConfirmLargeBatchStub        func(int, processor.CreditEstimate) bool
 	confirmLargeBatchMutex       sync.RWMutex
 	confirmLargeBatchArgsForCall []struct {
 		arg1 int
+		arg2 processor.CreditEstimate
 	}
 	confirmLargeBatchReturns struct {
 		result1 bool
@@ -23,21 +24,23 @@ type FakePromptInterface struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakePromptInterface) ConfirmLargeBatch(arg1 int) bool {
+func (fake *FakePromptInterface) ConfirmLargeBatch(arg1 int, arg2 processor.CreditEstimate) bool {
 	fake.confirmLargeBatchMutex.Lock()
 	ret, specificReturn := fake.confirmLargeBatchReturnsOnCall[len(fake.confirmLargeBatchArgsForCall)]
 	fake.confirmLargeBatchArgsForCall = append(fake.confirmLargeBatchArgsForCall, struct {
 		arg1 int
-	}{arg1})
-	fake.recordInvocation("ConfirmLargeBatch", []interface{}{arg1})
+		arg2 processor.CreditEstimate
+	}{arg1, arg2})
+	stub := fake.ConfirmLargeBatchStub
+	fakeReturns := fake.confirmLargeBatchReturns
+	fake.recordInvocation("ConfirmLargeBatch", []interface{}{arg1, arg2})
 	fake.confirmLargeBatchMutex.Unlock()
-	if fake.ConfirmLargeBatchStub != nil {
-		return fake.ConfirmLargeBatchStub(arg1)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.confirmLargeBatchReturns
 	return fakeReturns.result1
 }
 
@@ -47,17 +50,17 @@ func (fake *FakePromptInterface) ConfirmLargeBatchCallCount() int {
 	return len(fake.confirmLargeBatchArgsForCall)
 }
 
-func (fake *FakePromptInterface) ConfirmLargeBatchCalls(stub func(int) bool) {
+func (fake *FakePromptInterface) ConfirmLargeBatchCalls(stub func(int, processor.CreditEstimate) bool) {
 	fake.confirmLargeBatchMutex.Lock()
 	defer fake.confirmLargeBatchMutex.Unlock()
 	fake.ConfirmLargeBatchStub = stub
 }
 
-func (fake *FakePromptInterface) ConfirmLargeBatchArgsForCall(i int) int {
+func (fake *FakePromptInterface) ConfirmLargeBatchArgsForCall(i int) (int, processor.CreditEstimate) {
 	fake.confirmLargeBatchMutex.RLock()
 	defer fake.confirmLargeBatchMutex.RUnlock()
 	argsForCall := fake.confirmLargeBatchArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakePromptInterface) ConfirmLargeBatchReturns(result1 bool) {
@@ -0,0 +1,127 @@
+package processor
+
+import (
+	"fmt"
+	"github.com/remove-bg/go/client"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const progressBarWidth = 30
+
+// ProgressNotifier redraws a single progress bar line as images complete.
+// Errors and retries are printed above the bar so they don't scroll away.
+type ProgressNotifier struct {
+	Writer    io.Writer
+	StartedAt time.Time
+	Now       func() time.Time
+
+	mutex     sync.Mutex
+	total     int
+	processed int
+	skipped   int
+	failed    int
+	credits   float64
+}
+
+func NewProgressNotifier(w io.Writer) *ProgressNotifier {
+	return &ProgressNotifier{Writer: w, StartedAt: time.Now(), Now: time.Now}
+}
+
+// Start the clock for the throughput and ETA, once any confirmation prompt
+// has been answered
+func (n *ProgressNotifier) Start() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.StartedAt = n.Now()
+}
+
+func (n *ProgressNotifier) Success(input string, output string, result client.Result, imageNumber int, totalImages int) {
+	n.update(totalImages, func() {
+		n.processed++
+		n.credits += result.CreditsCharged
+	})
+}
+
+func (n *ProgressNotifier) Skip(input string, existing string, imageNumber int, totalImages int) {
+	n.update(totalImages, func() {
+		n.skipped++
+	})
+}
+
+// Duplicates count as processed, without using any credits
+func (n *ProgressNotifier) Duplicate(input string, output string, original string, imageNumber int, totalImages int) {
+	n.update(totalImages, func() {
+		n.processed++
+	})
+}
+
+func (n *ProgressNotifier) Error(err error, input string, output string, imageNumber int, totalImages int) {
+	n.update(totalImages, func() {
+		n.failed++
+		n.printAbove(fmt.Sprintf("Failed %s: %s", input, err))
+	})
+}
+
+func (n *ProgressNotifier) Retry(err error, input string, attempt int, delay time.Duration, imageNumber int, totalImages int) {
+	n.update(totalImages, func() {
+		n.printAbove(fmt.Sprintf("Retrying %s in %s (attempt %d): %s", input, delay, attempt, err))
+	})
+}
+
+// Finish moves past the progress bar, so later output starts on a new line
+func (n *ProgressNotifier) Finish() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if n.total > 0 {
+		fmt.Fprintln(n.Writer)
+	}
+}
+
+func (n *ProgressNotifier) update(totalImages int, change func()) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.total = totalImages
+	change()
+	n.draw()
+}
+
+func (n *ProgressNotifier) printAbove(line string) {
+	fmt.Fprintf(n.Writer, "\r\033[K%s\n", line)
+}
+
+func (n *ProgressNotifier) draw() {
+	fmt.Fprintf(n.Writer, "\r\033[K%s", n.line())
+}
+
+func (n *ProgressNotifier) line() string {
+	completed := n.processed + n.skipped + n.failed
+	filled := 0
+	if n.total > 0 {
+		filled = progressBarWidth * completed / n.total
+	}
+
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled)
+	line := fmt.Sprintf("[%s] %d/%d | %d processed, %d skipped, %d failed | %g credits",
+		bar, completed, n.total, n.processed, n.skipped, n.failed, n.credits)
+
+	elapsed := n.Now().Sub(n.StartedAt)
+	if elapsed <= 0 {
+		return line
+	}
+
+	perMinute := float64(completed) / elapsed.Minutes()
+	line += fmt.Sprintf(" | %.1f/min", perMinute)
+
+	if remaining := n.total - completed; remaining > 0 && perMinute > 0 {
+		eta := time.Duration(float64(remaining) / perMinute * float64(time.Minute))
+		line += fmt.Sprintf(" | ETA %s", eta.Round(time.Second))
+	}
+
+	return line
+}
@@ -0,0 +1,86 @@
+package processor_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"bytes"
+	"errors"
+	"github.com/remove-bg/go/client"
+	"strings"
+	"time"
+
+	. "github.com/remove-bg/go/processor"
+)
+
+var _ = Describe("ProgressNotifier", func() {
+	var (
+		out     *bytes.Buffer
+		now     time.Time
+		subject *ProgressNotifier
+	)
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		now = start
+		subject = &ProgressNotifier{
+			Writer:    out,
+			StartedAt: start,
+			Now:       func() time.Time { return now },
+		}
+	})
+
+	lastLine := func() string {
+		lines := strings.Split(out.String(), "\r\033[K")
+		return lines[len(lines)-1]
+	}
+
+	It("redraws the bar with the counts, throughput, credits and ETA", func() {
+		now = now.Add(time.Minute)
+		subject.Success("in/a.jpg", "out/a.png", client.Result{CreditsCharged: 1}, 1, 4)
+		subject.Skip("in/b.jpg", "out/b.png", 2, 4)
+
+		Expect(lastLine()).To(Equal(
+			"[###############---------------] 2/4 | 1 processed, 1 skipped, 0 failed | 1 credits | 2.0/min | ETA 1m0s"))
+	})
+
+	It("measures throughput from when it was started", func() {
+		now = now.Add(time.Hour)
+		subject.Start()
+		now = now.Add(time.Minute)
+		subject.Success("in/a.jpg", "out/a.png", client.Result{}, 1, 2)
+
+		Expect(lastLine()).To(HaveSuffix("| 1.0/min | ETA 1m0s"))
+	})
+
+	It("counts duplicates as processed", func() {
+		subject.Success("in/a.jpg", "out/a.png", client.Result{CreditsCharged: 1}, 1, 2)
+		subject.Duplicate("in/b.jpg", "out/b.png", "in/a.jpg", 2, 2)
+
+		Expect(lastLine()).To(HavePrefix("[##############################] 2/2 | 2 processed, 0 skipped, 0 failed | 1 credits"))
+	})
+
+	It("prints errors above the bar", func() {
+		now = now.Add(time.Minute)
+		subject.Success("in/a.jpg", "out/a.png", client.Result{}, 1, 2)
+		subject.Error(errors.New("boom"), "in/b.jpg", "out/b.png", 2, 2)
+
+		Expect(out.String()).To(ContainSubstring("\r\033[KFailed in/b.jpg: boom\n"))
+		Expect(lastLine()).To(HavePrefix("[##############################] 2/2 | 1 processed, 0 skipped, 1 failed"))
+		Expect(lastLine()).ToNot(ContainSubstring("ETA"))
+	})
+
+	It("prints retries above the bar", func() {
+		subject.Retry(errors.New("boom"), "in/a.jpg", 1, 2*time.Second, 1, 2)
+
+		Expect(out.String()).To(ContainSubstring("Retrying in/a.jpg in 2s (attempt 1): boom\n"))
+	})
+
+	It("ends the bar's line when finished", func() {
+		subject.Skip("in/a.jpg", "out/a.png", 1, 1)
+		subject.Finish()
+
+		Expect(out.String()).To(HaveSuffix("\n"))
+	})
+})
@@ -7,18 +7,25 @@ import (
 
 //go:generate counterfeiter . PromptInterface
 type PromptInterface interface {
-	ConfirmLargeBatch(size int) bool
+	ConfirmLargeBatch(size int, estimate CreditEstimate) bool
 }
 
 type Prompt struct {
 }
 
-func (Prompt) ConfirmLargeBatch(size int) bool {
+func (Prompt) ConfirmLargeBatch(size int, estimate CreditEstimate) bool {
 	confirmation := false
-	message := fmt.Sprintf("Do you want to process %d images?", size)
 	prompt := &survey.Confirm{
-		Message: message,
+		Message: ConfirmLargeBatchMessage(size, estimate),
 	}
 	survey.AskOne(prompt, &confirmation, nil)
 	return confirmation
 }
+
+func ConfirmLargeBatchMessage(size int, estimate CreditEstimate) string {
+	if estimate.BalanceKnown {
+		return fmt.Sprintf("Do you want to process %d images? (estimated %g credits, %g available)", size, estimate.Credits, estimate.Balance)
+	}
+
+	return fmt.Sprintf("Do you want to process %d images? (estimated %g credits)", size, estimate.Credits)
+}
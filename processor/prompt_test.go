@@ -0,0 +1,22 @@
+package processor_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/remove-bg/go/processor"
+)
+
+var _ = Describe("ConfirmLargeBatchMessage", func() {
+	It("shows the estimated credits against the balance", func() {
+		estimate := CreditEstimate{Credits: 60, Balance: 200, BalanceKnown: true}
+
+		Expect(ConfirmLargeBatchMessage(60, estimate)).To(Equal("Do you want to process 60 images? (estimated 60 credits, 200 available)"))
+	})
+
+	It("omits the balance if it's unknown", func() {
+		estimate := CreditEstimate{Credits: 15}
+
+		Expect(ConfirmLargeBatchMessage(60, estimate)).To(Equal("Do you want to process 60 images? (estimated 15 credits)"))
+	})
+})
@@ -0,0 +1,121 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/remove-bg/go/client"
+	"github.com/remove-bg/go/composite"
+	"io/ioutil"
+	"log"
+	"strings"
+	"time"
+)
+
+var errRenderURL = errors.New("Only local files can be rendered")
+
+// Render regenerates the outputs from cached masks and the original images,
+// without calling the API. The image settings must match those the images
+// were processed with, apart from the background and output format.
+func (p Processor) Render(ctx context.Context, rawInputPaths []string, settings Settings) Summary {
+	err := p.Storage.MkdirP(settings.OutputDirectory)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	inputPaths, err := p.Storage.ExpandPaths(rawInputPaths)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	options, err := renderOptions(settings)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	startedAt := time.Now()
+	totalImages := len(inputPaths)
+	summary := Summary{Total: totalImages}
+	params := settings.APIParams()
+
+	for index, inputPath := range inputPaths {
+		if ctx.Err() != nil {
+			break
+		}
+
+		imageNumber := index + 1
+		planned := p.planImage(inputPath, settings, map[string]JournalEntry{})
+
+		if planned.Action == PlanSkip {
+			p.Notifier.Skip(inputPath, planned.Output, imageNumber, totalImages)
+			summary.record(inputPath, imageOutcome{result: imageSkipped, output: planned.Output})
+			continue
+		}
+
+		outcome := imageOutcome{result: imageProcessed, output: planned.Output}
+		outcome.err = p.renderImage(ctx, planned, params, options)
+
+		if outcome.err == nil {
+			p.Notifier.Success(inputPath, planned.Output, client.Result{}, imageNumber, totalImages)
+		} else {
+			p.Notifier.Error(outcome.err, inputPath, planned.Output, imageNumber, totalImages)
+			outcome.result = imageFailed
+		}
+
+		summary.record(inputPath, outcome)
+	}
+
+	summary.Cancelled = ctx.Err() != nil
+	summary.Duration = time.Since(startedAt)
+	return summary
+}
+
+// renderOptions always use the output format, as without a background it would
+// otherwise be left to the API
+func renderOptions(settings Settings) (composite.Options, error) {
+	options, err := settings.compositeOptions()
+	if err != nil {
+		return options, err
+	}
+
+	switch strings.ToLower(settings.ImageSettings.OutputFormat) {
+	case "", FormatPng:
+		options.Format = composite.FormatPng
+	case "jpg", "jpeg":
+		options.Format = composite.FormatJpg
+	default:
+		return options, fmt.Errorf("Unable to render %s images, only png and jpg are supported", settings.ImageSettings.OutputFormat)
+	}
+
+	return options, nil
+}
+
+func (p Processor) renderImage(ctx context.Context, planned PlannedImage, params map[string]string, options composite.Options) error {
+	if IsURL(planned.Input) {
+		return errRenderURL
+	}
+
+	if len(planned.InputHash) == 0 {
+		return errors.New("Unable to read file")
+	}
+
+	mask, err := p.MaskCache.Get(MaskCacheKey(planned.InputHash, params))
+	if err != nil {
+		return err
+	}
+
+	colorImage, err := ioutil.ReadFile(planned.Input)
+	if err != nil {
+		return err
+	}
+
+	rendered := new(bytes.Buffer)
+
+	err = p.Compositor.Render(ctx, bytes.NewReader(colorImage), bytes.NewReader(mask), rendered, options)
+	if err != nil {
+		return err
+	}
+
+	return p.Storage.Write(compositeOutputPath(planned.Output, options), rendered.Bytes())
+}
@@ -0,0 +1,157 @@
+package processor_test
+
+import (
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/composite"
+	"github.com/remove-bg/go/composite/compositefakes"
+	"github.com/remove-bg/go/processor"
+	"github.com/remove-bg/go/processor/processorfakes"
+	"github.com/remove-bg/go/storage/storagefakes"
+	"io"
+	"io/ioutil"
+)
+
+var _ = Describe("Render", func() {
+	const input = "../fixtures/person-in-field.jpg"
+
+	var (
+		fakeStorage    *storagefakes.FakeStorageInterface
+		fakeNotifier   *processorfakes.FakeNotifierInterface
+		fakeCompositor *compositefakes.FakeCompositorInterface
+		fakeMaskCache  *processorfakes.FakeMaskCacheInterface
+		subject        processor.Processor
+		testSettings   processor.Settings
+	)
+
+	BeforeEach(func() {
+		fakeStorage = &storagefakes.FakeStorageInterface{}
+		fakeNotifier = &processorfakes.FakeNotifierInterface{}
+		fakeCompositor = &compositefakes.FakeCompositorInterface{}
+		fakeMaskCache = &processorfakes.FakeMaskCacheInterface{}
+		fakeStorage.ExpandPathsStub = func(input []string) ([]string, error) {
+			return input, nil
+		}
+		fakeStorage.ChecksumStub = func(path string) (string, error) {
+			return "hash-of-" + path, nil
+		}
+
+		fakeMaskCache.GetReturns([]byte("mask"), nil)
+		fakeCompositor.RenderStub = func(ctx context.Context, colorImage io.Reader, mask io.Reader, output io.Writer, options composite.Options) error {
+			_, err := output.Write([]byte("rendered"))
+			return err
+		}
+
+		subject = processor.Processor{
+			Storage:    fakeStorage,
+			Notifier:   fakeNotifier,
+			Compositor: fakeCompositor,
+			MaskCache:  fakeMaskCache,
+		}
+
+		testSettings = processor.Settings{
+			OutputDirectory: "output-dir",
+			ImageSettings: processor.ImageSettings{
+				Size:         "full",
+				OutputFormat: "png",
+			},
+		}
+	})
+
+	It("renders the cached mask with the original image", func() {
+		summary := subject.Render(context.Background(), []string{input}, testSettings)
+
+		Expect(summary.Processed).To(Equal(1))
+		Expect(fakeMaskCache.GetArgsForCall(0)).To(Equal(processor.MaskCacheKey("hash-of-"+input, map[string]string{"size": "full"})))
+
+		Expect(fakeCompositor.RenderCallCount()).To(Equal(1))
+		_, colorImage, mask, _, _ := fakeCompositor.RenderArgsForCall(0)
+
+		original, err := ioutil.ReadFile(input)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ioutil.ReadAll(colorImage)).To(Equal(original))
+		Expect(ioutil.ReadAll(mask)).To(Equal([]byte("mask")))
+
+		path, data := fakeStorage.WriteArgsForCall(0)
+		Expect(path).To(Equal("output-dir/person-in-field.png"))
+		Expect(data).To(Equal([]byte("rendered")))
+		Expect(fakeNotifier.SuccessCallCount()).To(Equal(1))
+	})
+
+	It("applies the background and format", func() {
+		testSettings.ImageSettings.OutputFormat = "jpg"
+		testSettings.ImageSettings.BgColor = "fff"
+
+		subject.Render(context.Background(), []string{input}, testSettings)
+
+		_, _, _, _, options := fakeCompositor.RenderArgsForCall(0)
+		Expect(options.Format).To(Equal(composite.FormatJpg))
+		Expect(options.Background.IsSet()).To(BeTrue())
+
+		path, _ := fakeStorage.WriteArgsForCall(0)
+		Expect(path).To(Equal("output-dir/person-in-field.jpg"))
+	})
+
+	It("applies the format without a background", func() {
+		testSettings.ImageSettings.OutputFormat = "jpg"
+
+		subject.Render(context.Background(), []string{input}, testSettings)
+
+		_, _, _, _, options := fakeCompositor.RenderArgsForCall(0)
+		Expect(options.Format).To(Equal(composite.FormatJpg))
+		Expect(options.Background.IsSet()).To(BeFalse())
+
+		path, _ := fakeStorage.WriteArgsForCall(0)
+		Expect(path).To(Equal("output-dir/person-in-field.jpg"))
+	})
+
+	It("fails images without a cached mask", func() {
+		fakeMaskCache.GetReturns(nil, processor.ErrMaskNotCached)
+
+		summary := subject.Render(context.Background(), []string{input}, testSettings)
+
+		Expect(summary.Failed).To(Equal(1))
+		Expect(summary.Errors[0].Message).To(Equal(processor.ErrMaskNotCached.Error()))
+		Expect(fakeCompositor.RenderCallCount()).To(Equal(0))
+		Expect(fakeStorage.WriteCallCount()).To(Equal(0))
+		Expect(fakeNotifier.ErrorCallCount()).To(Equal(1))
+	})
+
+	It("fails URLs", func() {
+		summary := subject.Render(context.Background(), []string{"https://example.com/a.jpg"}, testSettings)
+
+		Expect(summary.Failed).To(Equal(1))
+		Expect(fakeMaskCache.GetCallCount()).To(Equal(0))
+	})
+
+	It("doesn't write the output when rendering fails", func() {
+		fakeCompositor.RenderStub = nil
+		fakeCompositor.RenderReturns(errors.New("bad mask"))
+
+		summary := subject.Render(context.Background(), []string{input}, testSettings)
+
+		Expect(summary.Failed).To(Equal(1))
+		Expect(fakeStorage.WriteCallCount()).To(Equal(0))
+	})
+
+	It("skips existing outputs", func() {
+		fakeStorage.FileExistsReturns(true)
+
+		summary := subject.Render(context.Background(), []string{input}, testSettings)
+
+		Expect(summary.Skipped).To(Equal(1))
+		Expect(fakeCompositor.RenderCallCount()).To(Equal(0))
+	})
+
+	It("stops when cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		summary := subject.Render(ctx, []string{input}, testSettings)
+
+		Expect(summary.Cancelled).To(BeTrue())
+		Expect(summary.Remaining()).To(Equal(1))
+	})
+})
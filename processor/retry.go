@@ -0,0 +1,62 @@
+package processor
+
+import (
+	"github.com/remove-bg/go/client"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// RetryPolicy controls retrying images after transient failures, such as
+// server errors or network blips
+type RetryPolicy struct {
+	Attempts           int
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+	Jitter             float64
+	RetryableStatuses  []int
+	RetryNetworkErrors bool
+}
+
+var DefaultRetryableStatuses = []int{500, 502, 503, 504}
+
+func (r RetryPolicy) Retryable(err error) bool {
+	switch e := err.(type) {
+	case *client.RequestError:
+		for _, status := range r.RetryableStatuses {
+			if e.StatusCode == status {
+				return true
+			}
+		}
+	case net.Error:
+		return r.RetryNetworkErrors
+	}
+
+	return false
+}
+
+// Delay before the given retry (starting at 1), doubling each time
+func (r RetryPolicy) Delay(retry int) time.Duration {
+	delay := r.BaseDelay
+	for i := 1; i < retry && (r.MaxDelay <= 0 || delay < r.MaxDelay); i++ {
+		delay *= 2
+	}
+
+	if r.MaxDelay > 0 && delay > r.MaxDelay {
+		delay = r.MaxDelay
+	}
+
+	if r.Jitter > 0 {
+		delay += time.Duration(rand.Float64() * r.Jitter * float64(delay))
+	}
+
+	return delay
+}
+
+func (r RetryPolicy) retryDelay(err error, retry int) (time.Duration, bool) {
+	if retry >= r.Attempts || !r.Retryable(err) {
+		return 0, false
+	}
+
+	return r.Delay(retry), true
+}
@@ -0,0 +1,66 @@
+package processor_test
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/client"
+	"net"
+	"time"
+
+	. "github.com/remove-bg/go/processor"
+)
+
+var _ = Describe("RetryPolicy", func() {
+	var subject RetryPolicy
+
+	BeforeEach(func() {
+		subject = RetryPolicy{
+			Attempts:           3,
+			BaseDelay:          time.Second,
+			MaxDelay:           5 * time.Second,
+			RetryableStatuses:  DefaultRetryableStatuses,
+			RetryNetworkErrors: true,
+		}
+	})
+
+	Describe("Retryable", func() {
+		It("retries the configured HTTP statuses", func() {
+			Expect(subject.Retryable(&client.RequestError{StatusCode: 503})).To(BeTrue())
+			Expect(subject.Retryable(&client.RequestError{StatusCode: 501})).To(BeFalse())
+			Expect(subject.Retryable(&client.RequestError{StatusCode: 400})).To(BeFalse())
+		})
+
+		It("retries network errors if enabled", func() {
+			err := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+			Expect(subject.Retryable(err)).To(BeTrue())
+
+			subject.RetryNetworkErrors = false
+			Expect(subject.Retryable(err)).To(BeFalse())
+		})
+
+		It("doesn't retry other errors", func() {
+			Expect(subject.Retryable(errors.New("Unable to read file"))).To(BeFalse())
+		})
+	})
+
+	Describe("Delay", func() {
+		It("backs off exponentially up to the max delay", func() {
+			Expect(subject.Delay(1)).To(Equal(1 * time.Second))
+			Expect(subject.Delay(2)).To(Equal(2 * time.Second))
+			Expect(subject.Delay(3)).To(Equal(4 * time.Second))
+			Expect(subject.Delay(4)).To(Equal(5 * time.Second))
+			Expect(subject.Delay(100)).To(Equal(5 * time.Second))
+		})
+
+		It("adds jitter as a fraction of the delay", func() {
+			subject.Jitter = 0.5
+
+			for i := 0; i < 10; i++ {
+				delay := subject.Delay(2)
+				Expect(delay).To(BeNumerically(">=", 2*time.Second))
+				Expect(delay).To(BeNumerically("<=", 3*time.Second))
+			}
+		})
+	})
+})
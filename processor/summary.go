@@ -0,0 +1,111 @@
+package processor
+
+import (
+	"encoding/json"
+	"github.com/remove-bg/go/client"
+	"time"
+)
+
+// Summary of a batch once processing has finished or been cancelled
+type Summary struct {
+	Total          int           `json:"total"`
+	Processed      int           `json:"processed"`
+	Skipped        int           `json:"skipped"`
+	Failed         int           `json:"failed"`
+	Credits        float64       `json:"credits"`
+	Duplicates     int           `json:"duplicates"`    // Copied from an identical image, included in Processed
+	CreditsSaved   float64       `json:"credits_saved"` // By copying duplicates
+	Duration       time.Duration `json:"-"`
+	Cancelled      bool          `json:"cancelled"`
+	BudgetExceeded bool          `json:"budget_exceeded"`
+	RateLimited    bool          `json:"rate_limited"`
+	AuthFailed     bool          `json:"auth_failed"`
+	Errors         []FileError   `json:"errors"`
+}
+
+// FileError is why an image failed. The status code is only set for errors
+// returned by the API.
+type FileError struct {
+	Input      string `json:"input"`
+	Output     string `json:"output"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Message    string `json:"message"`
+}
+
+// Remaining images which were never attempted
+func (s Summary) Remaining() int {
+	return s.Total - s.Processed - s.Skipped - s.Failed
+}
+
+// MarshalJSON adds the remaining images and reports the duration in seconds
+func (s Summary) MarshalJSON() ([]byte, error) {
+	type summary Summary
+
+	if s.Errors == nil {
+		s.Errors = []FileError{}
+	}
+
+	return json.Marshal(struct {
+		summary
+		Remaining       int     `json:"remaining"`
+		DurationSeconds float64 `json:"duration_seconds"`
+	}{
+		summary:         summary(s),
+		Remaining:       s.Remaining(),
+		DurationSeconds: s.Duration.Seconds(),
+	})
+}
+
+type imageResult int
+
+const (
+	imageNotStarted imageResult = iota
+	imageProcessed
+	imageSkipped
+	imageFailed
+)
+
+// imageOutcome is what happened to a single image in the batch
+type imageOutcome struct {
+	result    imageResult
+	credits   float64
+	output    string
+	err       error
+	duplicate bool
+	saved     float64 // Credits the original cost, for a duplicate
+}
+
+func (s *Summary) record(input string, outcome imageOutcome) {
+	s.Credits += outcome.credits
+
+	switch outcome.result {
+	case imageProcessed:
+		s.Processed++
+
+		if outcome.duplicate {
+			s.Duplicates++
+			s.CreditsSaved += outcome.saved
+		}
+	case imageSkipped:
+		s.Skipped++
+	case imageFailed:
+		s.Failed++
+		s.recordError(input, outcome)
+	}
+}
+
+func (s *Summary) recordError(input string, outcome imageOutcome) {
+	fileErr := FileError{Input: input, Output: outcome.output}
+
+	if outcome.err != nil {
+		fileErr.Message = outcome.err.Error()
+	}
+
+	if clientErr, ok := outcome.err.(*client.RequestError); ok {
+		fileErr.StatusCode = clientErr.StatusCode
+		s.RateLimited = s.RateLimited || clientErr.RateLimitExceeded()
+		s.AuthFailed = s.AuthFailed || clientErr.AuthenticationFailed()
+	}
+
+	s.Errors = append(s.Errors, fileErr)
+}
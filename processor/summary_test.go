@@ -0,0 +1,58 @@
+package processor_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/remove-bg/go/processor"
+)
+
+var _ = Describe("Summary", func() {
+	It("marshals to the report format", func() {
+		summary := Summary{
+			Total:        3,
+			Processed:    1,
+			Failed:       1,
+			Credits:      1,
+			Duplicates:   1,
+			CreditsSaved: 1,
+			Duration:     1500 * time.Millisecond,
+			RateLimited:  true,
+			Errors: []FileError{
+				{Input: "in/b.jpg", Output: "out/b.png", StatusCode: 429, Message: "429: Rate limit exceeded"},
+			},
+		}
+
+		report, err := json.Marshal(summary)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report).To(MatchJSON(`{
+			"total": 3,
+			"processed": 1,
+			"skipped": 0,
+			"failed": 1,
+			"remaining": 1,
+			"credits": 1,
+			"duplicates": 1,
+			"credits_saved": 1,
+			"duration_seconds": 1.5,
+			"cancelled": false,
+			"budget_exceeded": false,
+			"rate_limited": true,
+			"auth_failed": false,
+			"errors": [
+				{"input": "in/b.jpg", "output": "out/b.png", "status_code": 429, "message": "429: Rate limit exceeded"}
+			]
+		}`))
+	})
+
+	It("marshals an empty list of errors", func() {
+		report, err := json.Marshal(Summary{Total: 1, Processed: 1})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(report)).To(ContainSubstring(`"errors":[]`))
+	})
+})
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrQueueFull = errors.New("queue is full")
+
+// Limiter allows a number of requests to run at once, queuing the rest up to
+// a limit
+type Limiter struct {
+	slots     chan struct{}
+	maxQueued int
+
+	mutex  sync.Mutex
+	queued int
+}
+
+func NewLimiter(concurrency int, maxQueued int) *Limiter {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &Limiter{
+		slots:     make(chan struct{}, concurrency),
+		maxQueued: maxQueued,
+	}
+}
+
+// Acquire waits for a slot, returning the function to release it. It fails
+// immediately if the queue is full, or when the context is cancelled.
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	release := func() { <-l.slots }
+
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	l.mutex.Lock()
+	if l.queued >= l.maxQueued {
+		l.mutex.Unlock()
+		return nil, ErrQueueFull
+	}
+
+	l.queued++
+	l.mutex.Unlock()
+
+	defer func() {
+		l.mutex.Lock()
+		l.queued--
+		l.mutex.Unlock()
+	}()
+
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,62 @@
+package server_test
+
+import (
+	"context"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/remove-bg/go/server"
+)
+
+var _ = Describe("Limiter", func() {
+	It("queues requests once every slot is taken", func() {
+		subject := NewLimiter(1, 1)
+
+		release, err := subject.Acquire(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+
+		acquired := make(chan func())
+		go func() {
+			defer GinkgoRecover()
+
+			queuedRelease, err := subject.Acquire(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			acquired <- queuedRelease
+		}()
+
+		Consistently(acquired).ShouldNot(Receive())
+
+		release()
+
+		var queuedRelease func()
+		Eventually(acquired).Should(Receive(&queuedRelease))
+		queuedRelease()
+	})
+
+	It("rejects requests when the queue is full", func() {
+		subject := NewLimiter(1, 0)
+
+		release, err := subject.Acquire(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		defer release()
+
+		_, err = subject.Acquire(context.Background())
+
+		Expect(err).To(Equal(ErrQueueFull))
+	})
+
+	It("stops waiting when the context is cancelled", func() {
+		subject := NewLimiter(1, 1)
+
+		release, err := subject.Acquire(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		defer release()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = subject.Acquire(ctx)
+
+		Expect(err).To(Equal(context.Canceled))
+	})
+})
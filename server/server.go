@@ -0,0 +1,243 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"github.com/remove-bg/go/client"
+	"github.com/remove-bg/go/composite"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Endpoint mirrors the API, so internal clients only need to change the host
+const Endpoint = "/v1.0/removebg"
+
+const (
+	imageFileParam   = "image_file"
+	imageURLParam    = "image_url"
+	bgImageFileParam = "bg_image_file"
+	formatParam      = "format"
+	formatPng        = "png"
+	formatZip        = "zip"
+	mimeZip          = "application/zip"
+)
+
+// The API doesn't apply these to ZIP results, so PNGs using them are requested
+// as PNG rather than composited here
+var uncompositedParams = []string{"bg_color", "bg_image_url", "channels"}
+
+// Uploads larger than this are rejected without being read
+const maxUploadSize = 32 << 20
+
+// Server forwards images to the API using its own API key. Internal clients
+// authenticate with one of the access keys instead, if any are configured.
+type Server struct {
+	APIKey     string
+	AccessKeys []string
+	Client     client.ClientInterface
+	Compositor composite.CompositorInterface
+	Limiter    *Limiter
+}
+
+func New(apiKey string, version string) Server {
+	return Server{
+		APIKey: apiKey,
+		Client: client.Client{
+			Version:    version,
+			HTTPClient: http.Client{},
+		},
+		Compositor: composite.New(),
+		Limiter:    NewLimiter(1, 0),
+	}
+}
+
+func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != Endpoint {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if !s.authorized(r.Header.Get("X-Api-Key")) {
+		writeError(w, http.StatusForbidden, "API Key invalid")
+		return
+	}
+
+	release, err := s.Limiter.Acquire(r.Context())
+	if err == ErrQueueFull {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusServiceUnavailable, "Too many queued requests")
+		return
+	} else if err != nil {
+		return // The client went away while queued
+	}
+
+	defer release()
+
+	s.remove(w, r)
+}
+
+func (s Server) authorized(accessKey string) bool {
+	if len(s.AccessKeys) == 0 {
+		return true
+	}
+
+	// Every key is compared in constant time, so timing doesn't reveal them
+	authorized := false
+	for _, key := range s.AccessKeys {
+		if len(accessKey) > 0 && subtle.ConstantTimeCompare([]byte(accessKey), []byte(key)) == 1 {
+			authorized = true
+		}
+	}
+
+	return authorized
+}
+
+func (s Server) remove(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+
+	err := r.ParseMultipartForm(maxUploadSize)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Unable to read the multipart form")
+		return
+	}
+
+	defer r.MultipartForm.RemoveAll()
+
+	if _, ok := r.MultipartForm.File[bgImageFileParam]; ok {
+		writeError(w, http.StatusBadRequest, "bg_image_file isn't supported, use bg_image_url instead")
+		return
+	}
+
+	params := map[string]string{}
+	for key, values := range r.MultipartForm.Value {
+		if key != imageURLParam && len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	// Save bandwidth by requesting ZIP format, then composite the PNG here
+	composited := params[formatParam] == formatPng && compositable(params)
+	if composited {
+		params[formatParam] = formatZip
+	}
+
+	result, err := s.send(r, params)
+	if err != nil {
+		writeClientError(w, err)
+		return
+	}
+
+	if composited && strings.Contains(result.ContentType, mimeZip) {
+		result.Data, err = s.composite(r.Context(), result.Data)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Unable to composite image")
+			return
+		}
+
+		result.ContentType = "image/png"
+	}
+
+	writeResult(w, result)
+}
+
+func compositable(params map[string]string) bool {
+	for _, param := range uncompositedParams {
+		if len(params[param]) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s Server) send(r *http.Request, params map[string]string) (client.Result, error) {
+	if imageURL := r.MultipartForm.Value[imageURLParam]; len(imageURL) > 0 {
+		return s.Client.RemoveFromURL(r.Context(), imageURL[0], s.APIKey, params)
+	}
+
+	file, header, err := r.FormFile(imageFileParam)
+	if err != nil {
+		return client.Result{}, &client.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        errors.New("No image given, specify image_file or image_url"),
+		}
+	}
+
+	defer file.Close()
+
+	return s.Client.RemoveFromReader(r.Context(), file, header.Filename, s.APIKey, params)
+}
+
+func (s Server) composite(ctx context.Context, zipData []byte) ([]byte, error) {
+	png := new(bytes.Buffer)
+
+	err := s.Compositor.Composite(ctx, bytes.NewReader(zipData), int64(len(zipData)), png, composite.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	return png.Bytes(), nil
+}
+
+func writeResult(w http.ResponseWriter, result client.Result) {
+	header := w.Header()
+	header.Set("Content-Type", result.ContentType)
+	header.Set("X-Credits-Charged", strconv.FormatFloat(result.CreditsCharged, 'f', -1, 64))
+
+	if result.Width > 0 && result.Height > 0 {
+		header.Set("X-Width", strconv.Itoa(result.Width))
+		header.Set("X-Height", strconv.Itoa(result.Height))
+	}
+
+	if len(result.DetectedType) > 0 {
+		header.Set("X-Type", result.DetectedType)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(result.Data)
+}
+
+// API errors are passed through, anything else means the API wasn't reached
+func writeClientError(w http.ResponseWriter, err error) {
+	clientErr, ok := err.(*client.RequestError)
+	if !ok {
+		writeError(w, http.StatusBadGateway, "Unable to reach the remove.bg API")
+		return
+	}
+
+	if clientErr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(clientErr.RetryAfter.Seconds())))
+	}
+
+	writeJSONError(w, clientErr.StatusCode, jsonError{Title: clientErr.Err.Error(), Code: clientErr.Code})
+}
+
+func writeError(w http.ResponseWriter, statusCode int, title string) {
+	writeJSONError(w, statusCode, jsonError{Title: title})
+}
+
+func writeJSONError(w http.ResponseWriter, statusCode int, e jsonError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(jsonErrorResponse{Errors: []jsonError{e}})
+}
+
+// Matches the API's error format
+type jsonErrorResponse struct {
+	Errors []jsonError `json:"errors"`
+}
+
+type jsonError struct {
+	Title string `json:"title"`
+	Code  string `json:"code,omitempty"`
+}
@@ -0,0 +1,210 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/remove-bg/go/client"
+	"github.com/remove-bg/go/client/clientfakes"
+	"github.com/remove-bg/go/composite"
+	"github.com/remove-bg/go/composite/compositefakes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/remove-bg/go/server"
+)
+
+var _ = Describe("Server", func() {
+	var (
+		fakeClient     *clientfakes.FakeClientInterface
+		fakeCompositor *compositefakes.FakeCompositorInterface
+		subject        Server
+	)
+
+	newRequest := func(fields map[string]string, image string) *http.Request {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+
+		for key, value := range fields {
+			Expect(writer.WriteField(key, value)).To(Succeed())
+		}
+
+		if len(image) > 0 {
+			part, err := writer.CreateFormFile("image_file", "image.jpg")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = io.WriteString(part, image)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(writer.Close()).To(Succeed())
+
+		request := httptest.NewRequest("POST", Endpoint, body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		return request
+	}
+
+	serve := func(request *http.Request) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		subject.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		fakeClient = &clientfakes.FakeClientInterface{}
+		fakeCompositor = &compositefakes.FakeCompositorInterface{}
+		subject = Server{
+			APIKey:     "api-key",
+			Client:     fakeClient,
+			Compositor: fakeCompositor,
+			Limiter:    NewLimiter(1, 0),
+		}
+	})
+
+	It("removes the background of the uploaded image", func() {
+		fakeClient.RemoveFromReaderReturns(client.Result{
+			Data:           []byte("processed"),
+			ContentType:    "image/jpeg",
+			CreditsCharged: 1,
+			Width:          640,
+			Height:         480,
+			DetectedType:   "person",
+		}, nil)
+
+		response := serve(newRequest(map[string]string{"size": "preview", "format": "jpg"}, "original"))
+
+		Expect(response.Code).To(Equal(200))
+		Expect(response.Body.String()).To(Equal("processed"))
+		Expect(response.Header().Get("Content-Type")).To(Equal("image/jpeg"))
+		Expect(response.Header().Get("X-Credits-Charged")).To(Equal("1"))
+		Expect(response.Header().Get("X-Width")).To(Equal("640"))
+		Expect(response.Header().Get("X-Type")).To(Equal("person"))
+
+		Expect(fakeClient.RemoveFromReaderCallCount()).To(Equal(1))
+		_, image, fileName, apiKey, params := fakeClient.RemoveFromReaderArgsForCall(0)
+		Expect(ioutil.ReadAll(image)).To(Equal([]byte("original")))
+		Expect(fileName).To(Equal("image.jpg"))
+		Expect(apiKey).To(Equal("api-key"))
+		Expect(params).To(Equal(map[string]string{"size": "preview", "format": "jpg"}))
+	})
+
+	It("passes image URLs to the API", func() {
+		fakeClient.RemoveFromURLReturns(client.Result{Data: []byte("processed"), ContentType: "image/png"}, nil)
+
+		response := serve(newRequest(map[string]string{"image_url": "https://cdn.example/a.jpg"}, ""))
+
+		Expect(response.Code).To(Equal(200))
+		_, imageURL, _, params := fakeClient.RemoveFromURLArgsForCall(0)
+		Expect(imageURL).To(Equal("https://cdn.example/a.jpg"))
+		Expect(params).To(BeEmpty())
+	})
+
+	It("requests ZIP format for PNGs and composites the result", func() {
+		fakeClient.RemoveFromReaderReturns(client.Result{Data: []byte("zip"), ContentType: "application/zip"}, nil)
+		fakeCompositor.CompositeStub = func(ctx context.Context, zipData io.ReaderAt, size int64, output io.Writer, options composite.Options) error {
+			Expect(ioutil.ReadAll(io.NewSectionReader(zipData, 0, size))).To(Equal([]byte("zip")))
+			_, err := io.WriteString(output, "png")
+			return err
+		}
+
+		response := serve(newRequest(map[string]string{"format": "png"}, "original"))
+
+		Expect(response.Code).To(Equal(200))
+		Expect(response.Body.String()).To(Equal("png"))
+		Expect(response.Header().Get("Content-Type")).To(Equal("image/png"))
+
+		_, _, _, _, params := fakeClient.RemoveFromReaderArgsForCall(0)
+		Expect(params["format"]).To(Equal("zip"))
+	})
+
+	It("requests PNGs with a background or channels as PNG", func() {
+		fakeClient.RemoveFromReaderReturns(client.Result{Data: []byte("png"), ContentType: "image/png"}, nil)
+
+		for _, param := range []string{"bg_color", "bg_image_url", "channels"} {
+			response := serve(newRequest(map[string]string{"format": "png", param: "value"}, "original"))
+
+			Expect(response.Code).To(Equal(200))
+			Expect(response.Body.String()).To(Equal("png"))
+		}
+
+		_, _, _, _, params := fakeClient.RemoveFromReaderArgsForCall(0)
+		Expect(params).To(Equal(map[string]string{"format": "png", "bg_color": "value"}))
+		Expect(fakeClient.RemoveFromReaderCallCount()).To(Equal(3))
+		Expect(fakeCompositor.CompositeCallCount()).To(Equal(0))
+	})
+
+	It("responds with the API's errors", func() {
+		fakeClient.RemoveFromReaderReturns(client.Result{}, &client.RequestError{
+			StatusCode: 429,
+			Code:       "rate_limit_exceeded",
+			Err:        errors.New("Rate limit exceeded"),
+			RetryAfter: 30 * time.Second,
+		})
+
+		response := serve(newRequest(nil, "original"))
+
+		Expect(response.Code).To(Equal(429))
+		Expect(response.Header().Get("Retry-After")).To(Equal("30"))
+		Expect(response.Body.String()).To(MatchJSON(`{"errors": [{"title": "Rate limit exceeded", "code": "rate_limit_exceeded"}]}`))
+	})
+
+	It("responds bad gateway when the API can't be reached", func() {
+		fakeClient.RemoveFromReaderReturns(client.Result{}, errors.New("connection refused"))
+
+		response := serve(newRequest(nil, "original"))
+
+		Expect(response.Code).To(Equal(502))
+	})
+
+	It("requires an image", func() {
+		response := serve(newRequest(map[string]string{"size": "preview"}, ""))
+
+		Expect(response.Code).To(Equal(400))
+		Expect(fakeClient.RemoveFromReaderCallCount()).To(Equal(0))
+	})
+
+	It("only accepts POST requests", func() {
+		response := serve(httptest.NewRequest("GET", Endpoint, nil))
+
+		Expect(response.Code).To(Equal(405))
+	})
+
+	Context("with access keys", func() {
+		BeforeEach(func() {
+			subject.AccessKeys = []string{"team-a", "team-b"}
+			fakeClient.RemoveFromReaderReturns(client.Result{Data: []byte("processed"), ContentType: "image/png"}, nil)
+		})
+
+		It("accepts a configured key", func() {
+			request := newRequest(nil, "original")
+			request.Header.Set("X-Api-Key", "team-b")
+
+			Expect(serve(request).Code).To(Equal(200))
+		})
+
+		It("rejects a missing or unknown key", func() {
+			request := newRequest(nil, "original")
+			request.Header.Set("X-Api-Key", "team-c")
+
+			Expect(serve(request).Code).To(Equal(403))
+			Expect(serve(newRequest(nil, "original")).Code).To(Equal(403))
+			Expect(fakeClient.RemoveFromReaderCallCount()).To(Equal(0))
+		})
+	})
+
+	It("responds service unavailable when the queue is full", func() {
+		release, err := subject.Limiter.Acquire(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		defer release()
+
+		response := serve(newRequest(nil, "original"))
+
+		Expect(response.Code).To(Equal(503))
+		Expect(response.Header().Get("Retry-After")).To(Equal("1"))
+	})
+})
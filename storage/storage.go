@@ -1,27 +1,81 @@
 package storage
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"github.com/bmatcuk/doublestar"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
 //go:generate counterfeiter . StorageInterface
 type StorageInterface interface {
 	Write(path string, data []byte) error
+	Copy(sourcePath string, destinationPath string) error
 	FileExists(path string) bool
 	ExpandPaths(originalPaths []string) ([]string, error)
 	MkdirP(path string) error
+	Checksum(path string) (string, error)
 }
 
 type FileStorage struct {
 }
 
+// Write atomically, via a temporary file in the same directory, so an
+// interrupted write never leaves a truncated file at the path
 func (FileStorage) Write(path string, data []byte) error {
-	out, _ := os.Create(path)
-	defer out.Close()
+	dir, fileName := filepath.Split(path)
+	if len(dir) == 0 {
+		dir = "."
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+fileName+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := tmp.Name()
+
+	err = writeAndSync(tmp, data)
+	if err == nil {
+		err = os.Chmod(tmpPath, 0644)
+	}
+
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+
+	if err != nil {
+		os.Remove(tmpPath)
+	}
+
+	return err
+}
+
+// Copy atomically, like Write
+func (s FileStorage) Copy(sourcePath string, destinationPath string) error {
+	data, err := ioutil.ReadFile(sourcePath)
+	if err != nil {
+		return err
+	}
+
+	return s.Write(destinationPath, data)
+}
+
+func writeAndSync(file *os.File, data []byte) error {
+	_, err := file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
 
-	_, err := out.Write(data)
 	return err
 }
 
@@ -61,3 +115,21 @@ func (FileStorage) MkdirP(path string) error {
 
 	return os.MkdirAll(path, 0755)
 }
+
+// Checksum is the hex encoded SHA-256 of the file contents
+func (FileStorage) Checksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
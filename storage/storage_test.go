@@ -1,6 +1,8 @@
 package storage_test
 
 import (
+	"crypto/sha256"
+	"fmt"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"io/ioutil"
@@ -115,4 +117,110 @@ var _ = Describe("FileStorage", func() {
 			Expect(outputDir).To(BeADirectory())
 		})
 	})
+
+	Describe("Checksum", func() {
+		It("is the SHA-256 of the file contents", func() {
+			fixtureFile := path.Join(testDir, "../fixtures/person-in-field.jpg")
+			contents, err := ioutil.ReadFile(fixtureFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			checksum, err := subject.Checksum(fixtureFile)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checksum).To(Equal(fmt.Sprintf("%x", sha256.Sum256(contents))))
+		})
+
+		It("returns an error if the file doesn't exist", func() {
+			_, err := subject.Checksum(path.Join(testDir, "../fixtures/missing.jpg"))
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Write", func() {
+		var tmpDir string
+
+		BeforeEach(func() {
+			dir, err := ioutil.TempDir("", "write-spec")
+			Expect(err).ToNot(HaveOccurred())
+
+			tmpDir = dir
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		It("writes the data to the path", func() {
+			outputPath := path.Join(tmpDir, "image.png")
+
+			Expect(subject.Write(outputPath, []byte("data"))).To(Succeed())
+
+			written, err := ioutil.ReadFile(outputPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(written).To(Equal([]byte("data")))
+		})
+
+		It("replaces any existing file", func() {
+			outputPath := path.Join(tmpDir, "image.png")
+			Expect(ioutil.WriteFile(outputPath, []byte("previous data"), 0644)).To(Succeed())
+
+			Expect(subject.Write(outputPath, []byte("data"))).To(Succeed())
+
+			written, err := ioutil.ReadFile(outputPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(written).To(Equal([]byte("data")))
+		})
+
+		It("doesn't leave any temporary files behind", func() {
+			Expect(subject.Write(path.Join(tmpDir, "image.png"), []byte("data"))).To(Succeed())
+
+			files, err := ioutil.ReadDir(tmpDir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+			Expect(files[0].Name()).To(Equal("image.png"))
+		})
+
+		It("returns an error if the directory doesn't exist", func() {
+			outputPath := path.Join(tmpDir, "missing/image.png")
+
+			Expect(subject.Write(outputPath, []byte("data"))).ToNot(Succeed())
+			Expect(outputPath).ToNot(BeAnExistingFile())
+		})
+	})
+
+	Describe("Copy", func() {
+		var tmpDir string
+
+		BeforeEach(func() {
+			dir, err := ioutil.TempDir("", "copy-spec")
+			Expect(err).ToNot(HaveOccurred())
+
+			tmpDir = dir
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		It("copies the file to the destination", func() {
+			sourcePath := path.Join(tmpDir, "a.png")
+			destinationPath := path.Join(tmpDir, "b.png")
+			Expect(ioutil.WriteFile(sourcePath, []byte("data"), 0644)).To(Succeed())
+
+			Expect(subject.Copy(sourcePath, destinationPath)).To(Succeed())
+
+			copied, err := ioutil.ReadFile(destinationPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(copied).To(Equal([]byte("data")))
+			Expect(sourcePath).To(BeAnExistingFile())
+		})
+
+		It("returns an error if the source doesn't exist", func() {
+			destinationPath := path.Join(tmpDir, "b.png")
+
+			Expect(subject.Copy(path.Join(tmpDir, "missing.png"), destinationPath)).ToNot(Succeed())
+			Expect(destinationPath).ToNot(BeAnExistingFile())
+		})
+	})
 })
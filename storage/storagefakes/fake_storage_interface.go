@@ -8,6 +8,31 @@ import (
 )
 
 type FakeStorageInterface struct {
+	ChecksumStub        func(string) (string, error)
+	checksumMutex       sync.RWMutex
+	checksumArgsForCall []struct {
+		arg1 string
+	}
+	checksumReturns struct {
+		result1 string
+		result2 error
+	}
+	checksumReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	CopyStub        func(string, string) error
+	copyMutex       sync.RWMutex
+	copyArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	copyReturns struct {
+		result1 error
+	}
+	copyReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ExpandPathsStub        func([]string) ([]string, error)
 	expandPathsMutex       sync.RWMutex
 	expandPathsArgsForCall []struct {
@@ -59,6 +84,132 @@ type FakeStorageInterface struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeStorageInterface) Checksum(arg1 string) (string, error) {
+	fake.checksumMutex.Lock()
+	ret, specificReturn := fake.checksumReturnsOnCall[len(fake.checksumArgsForCall)]
+	fake.checksumArgsForCall = append(fake.checksumArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ChecksumStub
+	fakeReturns := fake.checksumReturns
+	fake.recordInvocation("Checksum", []interface{}{arg1})
+	fake.checksumMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStorageInterface) ChecksumCallCount() int {
+	fake.checksumMutex.RLock()
+	defer fake.checksumMutex.RUnlock()
+	return len(fake.checksumArgsForCall)
+}
+
+func (fake *FakeStorageInterface) ChecksumCalls(stub func(string) (string, error)) {
+	fake.checksumMutex.Lock()
+	defer fake.checksumMutex.Unlock()
+	fake.ChecksumStub = stub
+}
+
+func (fake *FakeStorageInterface) ChecksumArgsForCall(i int) string {
+	fake.checksumMutex.RLock()
+	defer fake.checksumMutex.RUnlock()
+	argsForCall := fake.checksumArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStorageInterface) ChecksumReturns(result1 string, result2 error) {
+	fake.checksumMutex.Lock()
+	defer fake.checksumMutex.Unlock()
+	fake.ChecksumStub = nil
+	fake.checksumReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStorageInterface) ChecksumReturnsOnCall(i int, result1 string, result2 error) {
+	fake.checksumMutex.Lock()
+	defer fake.checksumMutex.Unlock()
+	fake.ChecksumStub = nil
+	if fake.checksumReturnsOnCall == nil {
+		fake.checksumReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.checksumReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStorageInterface) Copy(arg1 string, arg2 string) error {
+	fake.copyMutex.Lock()
+	ret, specificReturn := fake.copyReturnsOnCall[len(fake.copyArgsForCall)]
+	fake.copyArgsForCall = append(fake.copyArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.CopyStub
+	fakeReturns := fake.copyReturns
+	fake.recordInvocation("Copy", []interface{}{arg1, arg2})
+	fake.copyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStorageInterface) CopyCallCount() int {
+	fake.copyMutex.RLock()
+	defer fake.copyMutex.RUnlock()
+	return len(fake.copyArgsForCall)
+}
+
+func (fake *FakeStorageInterface) CopyCalls(stub func(string, string) error) {
+	fake.copyMutex.Lock()
+	defer fake.copyMutex.Unlock()
+	fake.CopyStub = stub
+}
+
+func (fake *FakeStorageInterface) CopyArgsForCall(i int) (string, string) {
+	fake.copyMutex.RLock()
+	defer fake.copyMutex.RUnlock()
+	argsForCall := fake.copyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeStorageInterface) CopyReturns(result1 error) {
+	fake.copyMutex.Lock()
+	defer fake.copyMutex.Unlock()
+	fake.CopyStub = nil
+	fake.copyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStorageInterface) CopyReturnsOnCall(i int, result1 error) {
+	fake.copyMutex.Lock()
+	defer fake.copyMutex.Unlock()
+	fake.CopyStub = nil
+	if fake.copyReturnsOnCall == nil {
+		fake.copyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.copyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeStorageInterface) ExpandPaths(arg1 []string) ([]string, error) {
 	var arg1Copy []string
 	if arg1 != nil {
@@ -70,15 +221,16 @@ func (fake *FakeStorageInterface) ExpandPaths(arg1 []string) ([]string, error) {
 	fake.expandPathsArgsForCall = append(fake.expandPathsArgsForCall, struct {
 		arg1 []string
 	}{arg1Copy})
+	stub := fake.ExpandPathsStub
+	fakeReturns := fake.expandPathsReturns
 	fake.recordInvocation("ExpandPaths", []interface{}{arg1Copy})
 	fake.expandPathsMutex.Unlock()
-	if fake.ExpandPathsStub != nil {
-		return fake.ExpandPathsStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.expandPathsReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -133,15 +285,16 @@ func (fake *FakeStorageInterface) FileExists(arg1 string) bool {
 	fake.fileExistsArgsForCall = append(fake.fileExistsArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.FileExistsStub
+	fakeReturns := fake.fileExistsReturns
 	fake.recordInvocation("FileExists", []interface{}{arg1})
 	fake.fileExistsMutex.Unlock()
-	if fake.FileExistsStub != nil {
-		return fake.FileExistsStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.fileExistsReturns
 	return fakeReturns.result1
 }
 
@@ -193,15 +346,16 @@ func (fake *FakeStorageInterface) MkdirP(arg1 string) error {
 	fake.mkdirPArgsForCall = append(fake.mkdirPArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.MkdirPStub
+	fakeReturns := fake.mkdirPReturns
 	fake.recordInvocation("MkdirP", []interface{}{arg1})
 	fake.mkdirPMutex.Unlock()
-	if fake.MkdirPStub != nil {
-		return fake.MkdirPStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.mkdirPReturns
 	return fakeReturns.result1
 }
 
@@ -259,15 +413,16 @@ func (fake *FakeStorageInterface) Write(arg1 string, arg2 []byte) error {
 		arg1 string
 		arg2 []byte
 	}{arg1, arg2Copy})
+	stub := fake.WriteStub
+	fakeReturns := fake.writeReturns
 	fake.recordInvocation("Write", []interface{}{arg1, arg2Copy})
 	fake.writeMutex.Unlock()
-	if fake.WriteStub != nil {
-		return fake.WriteStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.writeReturns
 	return fakeReturns.result1
 }
 
@@ -316,6 +471,10 @@ func (fake *FakeStorageInterface) WriteReturnsOnCall(i int, result1 error) {
 func (fake *FakeStorageInterface) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.checksumMutex.RLock()
+	defer fake.checksumMutex.RUnlock()
+	fake.copyMutex.RLock()
+	defer fake.copyMutex.RUnlock()
 	fake.expandPathsMutex.RLock()
 	defer fake.expandPathsMutex.RUnlock()
 	fake.fileExistsMutex.RLock()
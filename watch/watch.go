@@ -0,0 +1,189 @@
+package watch
+
+import (
+	"context"
+	"github.com/fsnotify/fsnotify"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ImageExtensions are the files picked up from the watched directory
+var ImageExtensions = []string{".jpg", ".jpeg", ".png", ".webp"}
+
+// Watcher reports image files created in, or moved into, a directory once
+// they've stopped changing. Subdirectories aren't watched.
+type Watcher struct {
+	Directory string
+	Settle    time.Duration // How long a file must be unchanged before it's ready
+}
+
+// pendingFile is a file which may still be being written
+type pendingFile struct {
+	size      int64
+	modTime   time.Time
+	changedAt time.Time
+}
+
+// Run calls ready, one file at a time, for any images already in the
+// directory and then for each new image, until the context is cancelled
+func (w Watcher) Run(ctx context.Context, ready func(path string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	defer watcher.Close()
+
+	err = watcher.Add(w.Directory)
+	if err != nil {
+		return err
+	}
+
+	existing, err := w.existingImages()
+	if err != nil {
+		return err
+	}
+
+	readyFiles := make(chan string)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for path := range readyFiles {
+			ready(path)
+		}
+	}()
+
+	err = w.loop(ctx, watcher, existing, readyFiles)
+
+	close(readyFiles)
+	<-done
+
+	return err
+}
+
+func (w Watcher) loop(ctx context.Context, watcher *fsnotify.Watcher, existing []string, readyFiles chan<- string) error {
+	pending := map[string]pendingFile{}
+	var queue []string
+
+	for _, path := range existing {
+		pending[path] = pendingFile{size: -1, changedAt: time.Now()}
+	}
+
+	ticker := time.NewTicker(w.pollInterval())
+	defer ticker.Stop()
+
+	for {
+		// Only offer the next file once one is queued
+		var send chan<- string
+		var next string
+		if len(queue) > 0 {
+			send = readyFiles
+			next = queue[0]
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watcher.Errors:
+			return err
+		case event := <-watcher.Events:
+			if event.Op&(fsnotify.Create|fsnotify.Write) != 0 && isImage(event.Name) {
+				pending[event.Name] = pendingFile{size: -1, changedAt: time.Now()}
+			}
+		case now := <-ticker.C:
+			queue = append(queue, w.settled(pending, now)...)
+		case send <- next:
+			queue = queue[1:]
+		}
+	}
+}
+
+// settled removes and returns the pending files which haven't changed for
+// the settle time
+func (w Watcher) settled(pending map[string]pendingFile, now time.Time) []string {
+	var ready []string
+
+	for path, file := range pending {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			delete(pending, path) // Removed or moved away before it settled
+			continue
+		}
+
+		if info.Size() != file.size || !info.ModTime().Equal(file.modTime) {
+			pending[path] = pendingFile{size: info.Size(), modTime: info.ModTime(), changedAt: now}
+			continue
+		}
+
+		if now.Sub(file.changedAt) >= w.Settle {
+			delete(pending, path)
+			ready = append(ready, path)
+		}
+	}
+
+	sort.Strings(ready)
+	return ready
+}
+
+func (w Watcher) pollInterval() time.Duration {
+	interval := w.Settle / 4
+	if interval < 10*time.Millisecond {
+		return 10 * time.Millisecond
+	}
+
+	return interval
+}
+
+func (w Watcher) existingImages() ([]string, error) {
+	files, err := ioutil.ReadDir(w.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []string
+	for _, file := range files {
+		path := filepath.Join(w.Directory, file.Name())
+		if !file.IsDir() && isImage(path) {
+			images = append(images, path)
+		}
+	}
+
+	return images, nil
+}
+
+// MoveTo moves the file into a subdirectory alongside it, such as processed/
+func MoveTo(path string, subdirectory string) (string, error) {
+	dir := filepath.Join(filepath.Dir(path), subdirectory)
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	movedPath := filepath.Join(dir, filepath.Base(path))
+	return movedPath, os.Rename(path, movedPath)
+}
+
+// Hidden files are skipped, as they're usually partial downloads or the
+// temporary files of atomic writes
+func isImage(path string) bool {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, imageExt := range ImageExtensions {
+		if ext == imageExt {
+			return true
+		}
+	}
+
+	return false
+}
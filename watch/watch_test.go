@@ -0,0 +1,133 @@
+package watch_test
+
+import (
+	"context"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/remove-bg/go/watch"
+)
+
+var _ = Describe("Watcher", func() {
+	var (
+		dir     string
+		cancel  context.CancelFunc
+		stopped chan error
+		mutex   sync.Mutex
+		ready   []string
+	)
+
+	readyFiles := func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return append([]string{}, ready...)
+	}
+
+	start := func() {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		stopped = make(chan error, 1)
+		subject := Watcher{Directory: dir, Settle: 100 * time.Millisecond}
+
+		go func() {
+			stopped <- subject.Run(ctx, func(path string) {
+				mutex.Lock()
+				ready = append(ready, path)
+				mutex.Unlock()
+			})
+		}()
+
+		// Give the watcher time to start
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "removebg-watch")
+		Expect(err).ToNot(HaveOccurred())
+		ready = nil
+	})
+
+	AfterEach(func() {
+		cancel()
+		Eventually(stopped).Should(Receive(BeNil()))
+		os.RemoveAll(dir)
+	})
+
+	It("reports images already in the directory", func() {
+		Expect(ioutil.WriteFile(filepath.Join(dir, "a.jpg"), []byte("image"), 0644)).To(Succeed())
+
+		start()
+
+		Eventually(readyFiles).Should(Equal([]string{filepath.Join(dir, "a.jpg")}))
+	})
+
+	It("reports new images once they've stopped changing", func() {
+		start()
+
+		path := filepath.Join(dir, "b.png")
+		file, err := os.Create(path)
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 0; i < 4; i++ {
+			_, err = file.Write([]byte("part"))
+			Expect(err).ToNot(HaveOccurred())
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		Expect(readyFiles()).To(BeEmpty())
+		Expect(file.Close()).To(Succeed())
+
+		Eventually(readyFiles).Should(Equal([]string{path}))
+		Consistently(readyFiles, 300*time.Millisecond).Should(HaveLen(1))
+	})
+
+	It("reports images moved into the directory", func() {
+		other, err := ioutil.TempDir("", "removebg-watch-other")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(other)
+
+		original := filepath.Join(other, "c.jpeg")
+		Expect(ioutil.WriteFile(original, []byte("image"), 0644)).To(Succeed())
+
+		start()
+		Expect(os.Rename(original, filepath.Join(dir, "c.jpeg"))).To(Succeed())
+
+		Eventually(readyFiles).Should(Equal([]string{filepath.Join(dir, "c.jpeg")}))
+	})
+
+	It("ignores hidden files, other files and subdirectories", func() {
+		start()
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, ".d.jpg.tmp"), []byte("image"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, ".e.jpg"), []byte("image"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("text"), 0644)).To(Succeed())
+		Expect(os.Mkdir(filepath.Join(dir, "f.jpg"), 0755)).To(Succeed())
+
+		Consistently(readyFiles, 400*time.Millisecond).Should(BeEmpty())
+	})
+})
+
+var _ = Describe("MoveTo", func() {
+	It("moves the file into the subdirectory", func() {
+		dir, err := ioutil.TempDir("", "removebg-watch")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "a.jpg")
+		Expect(ioutil.WriteFile(path, []byte("image"), 0644)).To(Succeed())
+
+		moved, err := MoveTo(path, "processed")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(moved).To(Equal(filepath.Join(dir, "processed", "a.jpg")))
+		Expect(moved).To(BeAnExistingFile())
+		Expect(path).ToNot(BeAnExistingFile())
+	})
+})